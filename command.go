@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"plastiqu_co/config"
+	"plastiqu_co/helper/logger"
+	"plastiqu_co/helper/metric"
+	"plastiqu_co/repository"
+)
+
+// migrateTimeout membatasi lama penerapan migration, termasuk pembuatan index pada koleksi besar
+const migrateTimeout = 5 * time.Minute
+
+// runCommand menjalankan subcommand CLI, mis. `go run . keygen`.
+// Mengembalikan false jika args bukan subcommand yang dikenal.
+func runCommand(args []string) bool {
+	switch args[0] {
+	case "keygen":
+		keygen()
+	case "migrate":
+		migrate(args[1:])
+	default:
+		return false
+	}
+	return true
+}
+
+// keygen membangkitkan kunci token baru untuk ditambahkan ke key ring
+func keygen() {
+	id, hexKey, err := metric.GenerateKey()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to generate key:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Key ID:", id)
+	fmt.Println("Key:   ", hexKey)
+	fmt.Println()
+	fmt.Println("Append the entry below to PASETO_KEYS (comma separated) and set PASETO_ACTIVE_KEY")
+	fmt.Println("to start issuing tokens with it. Keep the previous key until its tokens have expired.")
+	fmt.Println()
+	fmt.Printf("%s:%s\n", id, hexKey)
+	fmt.Printf("PASETO_ACTIVE_KEY=%s\n", id)
+}
+
+// migrate menjalankan `migrate up` untuk menerapkan migration yang pending atau
+// `migrate status` untuk menampilkan migration yang sudah dan belum diterapkan
+func migrate(args []string) {
+	if len(args) != 1 || (args[0] != "up" && args[0] != "status") {
+		fmt.Fprintln(os.Stderr, "Usage: migrate up|status")
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid configuration:", err)
+		os.Exit(1)
+	}
+	// Log migration ke stderr agar stdout hanya berisi hasil perintah
+	slog.SetDefault(logger.New(os.Stderr, cfg.Level(), cfg.Env == config.EnvProduction))
+
+	db, err := config.Connect(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to connect to the database:", err)
+		os.Exit(1)
+	}
+	defer db.Disconnect(context.Background())
+	repos := repository.New(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	if args[0] == "up" {
+		applied, err := repos.Migrate(ctx)
+		for _, m := range applied {
+			fmt.Printf("Applied %d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Migration failed:", err)
+			os.Exit(1)
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+		return
+	}
+
+	statuses, err := repos.MigrationStatus(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to read migration status:", err)
+		os.Exit(1)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	w.Flush()
+}
@@ -0,0 +1,282 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Agar APP_TIMEZONE tetap valid di image tanpa database zona waktu
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+
+	DatabaseMongo  = "mongo"
+	DatabaseMemory = "memory"
+
+	MailOutbox = "outbox"
+	MailSMTP   = "smtp"
+)
+
+// Config adalah konfigurasi aplikasi. Nilai dibaca dari file JSON opsional yang
+// ditunjuk CONFIG_FILE, lalu ditimpa oleh environment variable di komentar tiap field.
+// Kredensial tidak pernah disimpan di repository.
+type Config struct {
+	Env             string   `json:"env"`               // APP_ENV: "development" (default) atau "production"
+	Port            string   `json:"port"`              // PORT, default "3600"
+	BaseURL         string   `json:"base_url"`          // APP_BASE_URL, default http://localhost:<port>
+	FrontendURL     string   `json:"frontend_url"`      // APP_FRONTEND_URL, default http://localhost:3000; tautan reset password membuka halaman di sini
+	Database        string   `json:"database"`          // DB_DRIVER: "mongo" (default) atau "memory" untuk menjalankan API tanpa MongoDB
+	MongoURI        string   `json:"mongo_uri"`         // MONGO_URI (wajib untuk driver mongo)
+	DBName          string   `json:"db_name"`           // MONGO_DB, default "plastiqu"
+	CORSOrigins     []string `json:"cors_origins"`      // CORS_ORIGINS, dipisah koma; "*" mengizinkan semua origin
+	TimeZone        string   `json:"timezone"`          // APP_TIMEZONE, default "Asia/Jakarta"
+	LogLevel        string   `json:"log_level"`         // LOG_LEVEL: "debug", "info" (default), "warn" atau "error"
+	PasetoKeysFile  string   `json:"paseto_keys_file"`  // PASETO_KEYS_FILE
+	PasetoKeys      string   `json:"paseto_keys"`       // PASETO_KEYS
+	PasetoActiveKey string   `json:"paseto_active_key"` // PASETO_ACTIVE_KEY
+	MigrateOnStart  bool     `json:"migrate_on_start"`  // MIGRATE_ON_START, default true; false jika migration dijalankan lewat `migrate up`
+	TrustedProxies  []string `json:"trusted_proxies"`   // TRUSTED_PROXIES, IP atau CIDR dipisah koma; hanya proxy ini yang boleh mengisi X-Forwarded-For
+	MailDriver      string   `json:"mail_driver"`       // MAIL_DRIVER: "outbox" (default, file .eml untuk pengembangan) atau "smtp" (wajib di production)
+	MailFrom        string   `json:"mail_from"`         // MAIL_FROM, default "no-reply@plastiqu.co"
+	MailOutboxDir   string   `json:"mail_outbox_dir"`   // MAIL_OUTBOX_DIR, default "outbox"
+	SMTPHost        string   `json:"smtp_host"`         // SMTP_HOST (wajib untuk driver smtp)
+	SMTPPort        string   `json:"smtp_port"`         // SMTP_PORT, default "587"
+	SMTPUsername    string   `json:"smtp_username"`     // SMTP_USERNAME, kosong berarti tanpa autentikasi
+	SMTPPassword    string   `json:"smtp_password"`     // SMTP_PASSWORD
+}
+
+// Load membaca konfigurasi dari CONFIG_FILE dan environment lalu memvalidasinya
+func Load() (Config, error) {
+	cfg := Config{
+		Env:            EnvDevelopment,
+		Port:           "3600",
+		FrontendURL:    "http://localhost:3000",
+		Database:       DatabaseMongo,
+		DBName:         "plastiqu",
+		CORSOrigins:    []string{"http://localhost:3000"},
+		TimeZone:       "Asia/Jakarta",
+		LogLevel:       "info",
+		MigrateOnStart: true,
+		MailDriver:     MailOutbox,
+		MailFrom:       "no-reply@plastiqu.co",
+		MailOutboxDir:  "outbox",
+		SMTPPort:       "587",
+	}
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("read config file: %w", err)
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+
+	setFromEnv(&cfg.Env, "APP_ENV")
+	setFromEnv(&cfg.Port, "PORT")
+	setFromEnv(&cfg.BaseURL, "APP_BASE_URL")
+	setFromEnv(&cfg.FrontendURL, "APP_FRONTEND_URL")
+	setFromEnv(&cfg.Database, "DB_DRIVER")
+	setFromEnv(&cfg.MongoURI, "MONGO_URI")
+	setFromEnv(&cfg.DBName, "MONGO_DB")
+	setFromEnv(&cfg.TimeZone, "APP_TIMEZONE")
+	setFromEnv(&cfg.LogLevel, "LOG_LEVEL")
+	setFromEnv(&cfg.PasetoKeysFile, "PASETO_KEYS_FILE")
+	setFromEnv(&cfg.PasetoKeys, "PASETO_KEYS")
+	setFromEnv(&cfg.PasetoActiveKey, "PASETO_ACTIVE_KEY")
+	setFromEnv(&cfg.MailDriver, "MAIL_DRIVER")
+	setFromEnv(&cfg.MailFrom, "MAIL_FROM")
+	setFromEnv(&cfg.MailOutboxDir, "MAIL_OUTBOX_DIR")
+	setFromEnv(&cfg.SMTPHost, "SMTP_HOST")
+	setFromEnv(&cfg.SMTPPort, "SMTP_PORT")
+	setFromEnv(&cfg.SMTPUsername, "SMTP_USERNAME")
+	setFromEnv(&cfg.SMTPPassword, "SMTP_PASSWORD")
+	if origins := os.Getenv("CORS_ORIGINS"); origins != "" {
+		cfg.CORSOrigins = splitList(origins)
+	}
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		cfg.TrustedProxies = splitList(proxies)
+	}
+	if value := os.Getenv("MIGRATE_ON_START"); value != "" {
+		migrate, err := strconv.ParseBool(value)
+		if err != nil {
+			return cfg, fmt.Errorf("MIGRATE_ON_START must be true or false, got %q", value)
+		}
+		cfg.MigrateOnStart = migrate
+	}
+
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "http://localhost:" + cfg.Port
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// Validate memeriksa semua field dan mengembalikan seluruh kesalahan sekaligus
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Env != EnvDevelopment && c.Env != EnvProduction {
+		errs = append(errs, fmt.Errorf("APP_ENV must be %q or %q, got %q", EnvDevelopment, EnvProduction, c.Env))
+	}
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be a number between 1 and 65535, got %q", c.Port))
+	}
+	if u, err := url.Parse(c.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("APP_BASE_URL must be an absolute URL, got %q", c.BaseURL))
+	}
+	if u, err := url.Parse(c.FrontendURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("APP_FRONTEND_URL must be an absolute URL, got %q", c.FrontendURL))
+	}
+	switch c.Database {
+	case DatabaseMongo:
+		if c.MongoURI == "" {
+			errs = append(errs, errors.New("MONGO_URI is required"))
+		} else if !strings.HasPrefix(c.MongoURI, "mongodb://") && !strings.HasPrefix(c.MongoURI, "mongodb+srv://") {
+			errs = append(errs, errors.New("MONGO_URI must start with mongodb:// or mongodb+srv://"))
+		}
+	case DatabaseMemory:
+		if c.Env == EnvProduction {
+			errs = append(errs, errors.New("DB_DRIVER memory is not allowed in production"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("DB_DRIVER must be %q or %q, got %q", DatabaseMongo, DatabaseMemory, c.Database))
+	}
+	if c.DBName == "" {
+		errs = append(errs, errors.New("MONGO_DB must not be empty"))
+	}
+	if len(c.CORSOrigins) == 0 {
+		errs = append(errs, errors.New("CORS_ORIGINS must contain at least one origin"))
+	}
+	for _, origin := range c.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			errs = append(errs, fmt.Errorf("CORS_ORIGINS entry %q must be an origin such as https://example.com", origin))
+		}
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, err := parseNetwork(proxy); err != nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES entry %q must be an IP address or CIDR range", proxy))
+		}
+	}
+	if _, err := time.LoadLocation(c.TimeZone); err != nil {
+		errs = append(errs, fmt.Errorf("APP_TIMEZONE %q is not a known time zone", c.TimeZone))
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	if c.Env == EnvProduction && c.PasetoKeysFile == "" && c.PasetoKeys == "" {
+		errs = append(errs, errors.New("PASETO_KEYS or PASETO_KEYS_FILE is required in production"))
+	}
+	if _, err := mail.ParseAddress(c.MailFrom); err != nil {
+		errs = append(errs, fmt.Errorf("MAIL_FROM must be an email address, got %q", c.MailFrom))
+	}
+	switch c.MailDriver {
+	case MailSMTP:
+		if c.SMTPHost == "" {
+			errs = append(errs, errors.New("SMTP_HOST is required when MAIL_DRIVER is smtp"))
+		}
+		if port, err := strconv.Atoi(c.SMTPPort); err != nil || port < 1 || port > 65535 {
+			errs = append(errs, fmt.Errorf("SMTP_PORT must be a number between 1 and 65535, got %q", c.SMTPPort))
+		}
+		if c.SMTPUsername != "" && c.SMTPPassword == "" {
+			errs = append(errs, errors.New("SMTP_PASSWORD is required when SMTP_USERNAME is set"))
+		}
+	case MailOutbox:
+		if c.Env == EnvProduction {
+			errs = append(errs, errors.New("MAIL_DRIVER outbox is not allowed in production; configure smtp"))
+		}
+		if c.MailOutboxDir == "" {
+			errs = append(errs, errors.New("MAIL_OUTBOX_DIR must not be empty"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("MAIL_DRIVER must be %q or %q, got %q", MailOutbox, MailSMTP, c.MailDriver))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Location mengembalikan zona waktu aplikasi. Hanya dipanggil setelah Validate berhasil.
+func (c Config) Location() *time.Location {
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// TrustedProxyNetworks mengembalikan TRUSTED_PROXIES sebagai jaringan. Hanya dipanggil setelah Validate berhasil.
+func (c Config) TrustedProxyNetworks() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, proxy := range c.TrustedProxies {
+		if network, err := parseNetwork(proxy); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// parseNetwork membaca CIDR seperti "10.0.0.0/8" atau satu IP seperti "10.0.0.1"
+func parseNetwork(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q", value)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(value)
+	return network, err
+}
+
+// Level mengembalikan level log aplikasi. Hanya dipanggil setelah Validate berhasil.
+func (c Config) Level() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// AllowsAnyOrigin bernilai true jika CORS_ORIGINS berisi "*"
+func (c Config) AllowsAnyOrigin() bool {
+	for _, origin := range c.CORSOrigins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func setFromEnv(field *string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*field = value
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"strconv"
+
+	"plastiqu_co/helper/mailer"
+)
+
+// Mailer membuat pengirim email transaksional (verifikasi, reset password) sesuai
+// MAIL_DRIVER. Hanya dipanggil setelah Validate berhasil.
+func (c Config) Mailer() mailer.Sender {
+	if c.MailDriver == MailSMTP {
+		port, _ := strconv.Atoi(c.SMTPPort)
+		return mailer.SMTPSender{
+			Host:     c.SMTPHost,
+			Port:     port,
+			Username: c.SMTPUsername,
+			Password: c.SMTPPassword,
+			From:     c.MailFrom,
+		}
+	}
+	return mailer.OutboxSender{Dir: c.MailOutboxDir, From: c.MailFrom}
+}
@@ -8,143 +8,126 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"plastiqu_co/config"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
 	"plastiqu_co/model"
 )
 
 // CreateAddress untuk menambahkan address baru
-func CreateAddress(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateAddress(w http.ResponseWriter, r *http.Request) {
 	var address model.Address
 	if err := json.NewDecoder(r.Body).Decode(&address); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request payload"})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
+		return
+	}
+
+	if err := validate.Struct(address); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
 	address.ID = primitive.NewObjectID()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("addresses")
-	_, err := collection.InsertOne(ctx, address)
+	collection := h.repos.Addresses
+	err := collection.Insert(ctx, address)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create address"})
+		response.WriteError(w, r, response.Internal("Failed to create address", "An error occurred while creating the address.").WithCause(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(address)
+	response.JSON(w, http.StatusCreated, address)
 }
 
 // GetAddresses untuk mendapatkan semua address
-func GetAddresses(w http.ResponseWriter, r *http.Request) {
-	var addresses []model.Address
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (h *Handler) GetAddresses(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("addresses")
-	cursor, err := collection.Find(ctx, bson.M{})
+	collection := h.repos.Addresses
+	addresses, err := collection.Find(ctx, bson.M{})
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to retrieve addresses"})
+		response.WriteError(w, r, response.Internal("Failed to retrieve addresses", "An error occurred while retrieving addresses.").WithCause(err))
 		return
 	}
-	defer cursor.Close(ctx)
-
-	for cursor.Next(ctx) {
-		var address model.Address
-		if err := cursor.Decode(&address); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to decode address data"})
-			return
-		}
-		addresses = append(addresses, address)
-	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(addresses)
+	response.JSON(w, http.StatusOK, addresses)
 }
 
 // GetAddressByID untuk mendapatkan address berdasarkan ID
-func GetAddressByID(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetAddressByID(w http.ResponseWriter, r *http.Request) {
 	id, err := primitive.ObjectIDFromHex(r.URL.Query().Get("id"))
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid address ID"})
+		response.WriteError(w, r, response.BadRequest("Invalid address ID", "Address ID format is incorrect."))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
 	var address model.Address
-	collection := config.Mongoconn.Collection("addresses")
-	err = collection.FindOne(ctx, bson.M{"_id": id}).Decode(&address)
+	collection := h.repos.Addresses
+	address, err = collection.FindOne(ctx, bson.M{"_id": id})
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Address not found"})
+		response.WriteError(w, r, response.NotFound("Address not found", "No address found with the specified ID."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(address)
+	response.JSON(w, http.StatusOK, address)
 }
 
 // UpdateAddress untuk memperbarui address berdasarkan ID
-func UpdateAddress(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateAddress(w http.ResponseWriter, r *http.Request) {
 	id, err := primitive.ObjectIDFromHex(r.URL.Query().Get("id"))
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid address ID"})
+		response.WriteError(w, r, response.BadRequest("Invalid address ID", "Address ID format is incorrect."))
 		return
 	}
 
 	var address model.Address
 	if err := json.NewDecoder(r.Body).Decode(&address); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request payload"})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
+		return
+	}
+
+	if err := validate.Patch(address); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("addresses")
+	collection := h.repos.Addresses
 	update := bson.M{"$set": address}
 	result, err := collection.UpdateOne(ctx, bson.M{"_id": id}, update)
 	if err != nil || result.MatchedCount == 0 {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Address not found"})
+		response.WriteError(w, r, response.NotFound("Address not found", "No address found with the specified ID."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Address updated successfully"})
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Address updated successfully"})
 }
 
 // DeleteAddress untuk menghapus address berdasarkan ID
-func DeleteAddress(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeleteAddress(w http.ResponseWriter, r *http.Request) {
 	id, err := primitive.ObjectIDFromHex(r.URL.Query().Get("id"))
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid address ID"})
+		response.WriteError(w, r, response.BadRequest("Invalid address ID", "Address ID format is incorrect."))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("addresses")
+	collection := h.repos.Addresses
 	result, err := collection.DeleteOne(ctx, bson.M{"_id": id})
 	if err != nil || result.DeletedCount == 0 {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Address not found"})
+		response.WriteError(w, r, response.NotFound("Address not found", "No address found with the specified ID."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Address deleted successfully"})
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Address deleted successfully"})
 }
@@ -4,17 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
-	"plastiqu_co/config"
+	"plastiqu_co/controller/auth"
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
 	"plastiqu_co/model"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // AdminUpdateUserProfile allows an admin to update any user's profile
-func AdminUpdateUserProfile(w http.ResponseWriter, r *http.Request) {
-	adminID := r.URL.Query().Get("admin_id") // ambil admin_id dari query parameter
+func (h *Handler) AdminUpdateUserProfile(w http.ResponseWriter, r *http.Request) {
 	var updateData model.Users
 
 	// Get the user ID from the URL
@@ -23,120 +26,117 @@ func AdminUpdateUserProfile(w http.ResponseWriter, r *http.Request) {
 	// Convert userID string to ObjectID
 	objID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid user ID",
-			"message": "User ID format is incorrect.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid user ID", "User ID format is incorrect."))
 		return
 	}
 
 	// Decode the request body into updateData struct
 	err = json.NewDecoder(r.Body).Decode(&updateData)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
 		return
 	}
 
-	// Get admin user data to check role
-	collection := config.Mongoconn.Collection("users")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := validate.Patch(updateData); err != nil {
+		response.WriteError(w, r, err)
+		return
+	}
+
+	// Permission pemanggil sudah diverifikasi oleh middleware.RequirePermission pada router
+	collection := h.repos.Users
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	var adminUser model.Users
-	err = collection.FindOne(ctx, bson.M{"_id": adminID}).Decode(&adminUser)
-	if err != nil || adminUser.Role != "admin" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Access denied",
-			"message": "You do not have permission to perform this action.",
-		})
+	user, err := collection.FindByID(ctx, objID)
+	if err == mongo.ErrNoDocuments {
+		response.WriteError(w, r, response.NotFound("User not found", "The user with the specified ID does not exist."))
+		return
+	} else if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to update profile", "An error occurred while updating the profile.").WithCause(err))
+		return
+	}
+	if err := h.checkTargetUser(r, user); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
 	// Prepare the update fields
-	updateFields := bson.M{
-		"username": updateData.Username,
-		"phone":      updateData.Phone,
-		"image":      updateData.Image, // Nullable
-		"role":       updateData.Role,  // Admin can update role
-		"updated_at": time.Now(),
+	updateFields := profileFields(updateData)
+
+	// Perubahan role hanya boleh dilakukan oleh pemanggil dengan permission roles:manage,
+	// ke role yang ada, dan mengikuti aturan yang sama dengan AssignUserRole
+	roleChanged := false
+	if updateData.Role != "" {
+		allowed, err := h.guard.HasPermission(r.Context(), model.PermissionRolesManage)
+		if err != nil || !allowed {
+			response.WriteError(w, r, response.Forbidden("Access denied", "You do not have permission to change user roles."))
+			return
+		}
+		count, err := h.repos.Roles.Count(ctx, bson.M{"name": updateData.Role})
+		if err != nil {
+			response.WriteError(w, r, response.Internal("Failed to update profile", "An error occurred while updating the profile.").WithCause(err))
+			return
+		}
+		if count == 0 {
+			response.WriteError(w, r, response.FieldInvalid("role", "Role not found", "The role you are trying to assign does not exist."))
+			return
+		}
+		if user.Role != updateData.Role {
+			if err := checkRoleChange(r, objID, user.Role, updateData.Role); err != nil {
+				response.WriteError(w, r, err)
+				return
+			}
+			updateFields["role"] = updateData.Role
+			roleChanged = true
+		}
 	}
 
+	before := collection.Snapshot(ctx, bson.M{"_id": objID})
 	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": updateFields})
+	if fields, ok := auth.DuplicateKeyConflicts(err); ok {
+		response.WriteError(w, r, auth.ConflictError(fields))
+		return
+	}
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to update profile",
-			"message": "An error occurred while updating the profile.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to update profile", "An error occurred while updating the profile.").WithCause(err))
 		return
 	}
+	h.audit.Record(r, audit.Event{
+		Action:   "user.update",
+		Entity:   "users",
+		EntityID: objID.Hex(),
+		Before:   before,
+		After:    collection.Snapshot(ctx, bson.M{"_id": objID}),
+	})
+
+	// Sesi lama dicabut agar role baru langsung berlaku
+	if roleChanged {
+		if err := h.auth.RevokeUserRefreshTokens(ctx, objID); err != nil {
+			response.WriteError(w, r, response.Internal("Failed to revoke sessions", "The profile was updated, but existing sessions could not be revoked.").WithCause(err))
+			return
+		}
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "User profile updated successfully",
 	})
 }
 
 // AdminUpdateUserRole allows an admin to upgrade a user's role to admin
-func AdminUpdateUserRole(w http.ResponseWriter, r *http.Request) {
-	adminID := r.URL.Query().Get("admin_id") // ambil admin_id dari query parameter
-
+func (h *Handler) AdminUpdateUserRole(w http.ResponseWriter, r *http.Request) {
 	// Get the user ID from the URL
 	userID := r.URL.Query().Get("user_id")
 	
 	// Convert userID string to ObjectID
 	objID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid user ID",
-			"message": "User ID format is incorrect.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid user ID", "User ID format is incorrect."))
 		return
 	}
 
-	// Get admin user data to check role
-	collection := config.Mongoconn.Collection("users")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	var adminUser model.Users
-	err = collection.FindOne(ctx, bson.M{"_id": adminID}).Decode(&adminUser)
-	if err != nil || adminUser.Role != "admin" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Access denied",
-			"message": "You do not have permission to perform this action.",
-		})
-		return
-	}
-
-	// Update the user's role to admin
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"role": "admin", "updated_at": time.Now()}})
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to update role",
-			"message": "An error occurred while updating the user's role.",
-		})
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "User role updated to admin successfully",
-	})
+	// Hanya admin yang boleh memberikan role admin, dan tidak kepada dirinya sendiri
+	h.setUserRole(ctx, w, r, objID, model.RoleAdmin, "User role updated to admin successfully")
 }
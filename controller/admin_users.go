@@ -0,0 +1,302 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"time"
+
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/middleware"
+	"plastiqu_co/model"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// userPublicProjection menyembunyikan field sensitif saat data user dikirim ke admin
+var userPublicProjection = bson.M{"password": 0}
+
+// AdminListUsers menampilkan daftar pengguna dengan pencarian dan paginasi.
+// Query parameter: email, username, phone (pencarian sebagian, tidak peka huruf besar),
+// role, status, created_from, created_to, include_deleted, page, limit.
+func (h *Handler) AdminListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := bson.M{}
+
+	for _, field := range []string{"email", "username", "phone"} {
+		if value := query.Get(field); value != "" {
+			filter[field] = primitive.Regex{Pattern: regexp.QuoteMeta(value), Options: "i"}
+		}
+	}
+	if role := query.Get("role"); role != "" {
+		filter["role"] = role
+	}
+	if status := query.Get("status"); status != "" {
+		filter["status"] = status
+	} else if query.Get("include_deleted") != "true" {
+		filter["status"] = bson.M{"$ne": model.UserStatusDeleted}
+	}
+
+	createdFrom, err := parseDateParam(query.Get("created_from"), h.loc)
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid created_from", "Use RFC3339 or YYYY-MM-DD format."))
+		return
+	}
+	createdTo, err := parseDateParam(query.Get("created_to"), h.loc)
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid created_to", "Use RFC3339 or YYYY-MM-DD format."))
+		return
+	}
+	if len(query.Get("created_to")) == len("2006-01-02") {
+		// Tanggal tanpa jam mencakup seluruh hari tersebut
+		createdTo = createdTo.Add(24 * time.Hour)
+	}
+	if !createdFrom.IsZero() || !createdTo.IsZero() {
+		createdAt := bson.M{}
+		if !createdFrom.IsZero() {
+			createdAt["$gte"] = createdFrom
+		}
+		if !createdTo.IsZero() {
+			createdAt["$lt"] = createdTo
+		}
+		filter["created_at"] = createdAt
+	}
+
+	page, limit := pagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	collection := h.repos.Users
+	total, err := collection.Count(ctx, filter)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to retrieve users", "An error occurred while retrieving users.").WithCause(err))
+		return
+	}
+
+	opts := options.Find().
+		SetProjection(userPublicProjection).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((page - 1) * limit).
+		SetLimit(limit)
+	users, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to retrieve users", "An error occurred while retrieving users.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"data":  users,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+	})
+}
+
+// AdminGetUser menampilkan detail satu pengguna
+func (h *Handler) AdminGetUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid user ID", "User ID format is incorrect."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.repos.Users.FindOne(ctx, bson.M{"_id": userID}, options.FindOne().SetProjection(userPublicProjection))
+	if err != nil {
+		response.WriteError(w, r, response.NotFound("User not found", "The user with the specified ID does not exist."))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, user)
+}
+
+// SuspendUserRequest adalah body opsional untuk menangguhkan akun
+type SuspendUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+// AdminSuspendUser menangguhkan akun sehingga tidak bisa login, dan mencabut sesi yang ada
+func (h *Handler) AdminSuspendUser(w http.ResponseWriter, r *http.Request) {
+	var request SuspendUserRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
+			return
+		}
+	}
+
+	now := time.Now()
+	h.updateUserStatus(w, r, bson.M{
+		"status":         model.UserStatusSuspended,
+		"suspended_at":   now,
+		"suspend_reason": request.Reason,
+		"updated_at":     now,
+	}, "user.suspend", "User suspended successfully")
+}
+
+// AdminReactivateUser mengaktifkan kembali akun yang ditangguhkan
+func (h *Handler) AdminReactivateUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid user ID", "User ID format is incorrect."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	collection := h.repos.Users
+	target, err := collection.FindByID(ctx, userID)
+	if err == mongo.ErrNoDocuments {
+		response.WriteError(w, r, response.NotFound("User not found", "No suspended user with the specified ID exists."))
+		return
+	} else if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to reactivate user", "An error occurred while reactivating the user.").WithCause(err))
+		return
+	}
+	if err := h.checkTargetUser(r, target); err != nil {
+		response.WriteError(w, r, err)
+		return
+	}
+
+	before := collection.Snapshot(ctx, bson.M{"_id": userID})
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": userID, "status": model.UserStatusSuspended},
+		bson.M{
+			"$set":   bson.M{"status": model.UserStatusActive, "updated_at": time.Now()},
+			"$unset": bson.M{"suspended_at": "", "suspend_reason": ""},
+		},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to reactivate user", "An error occurred while reactivating the user.").WithCause(err))
+		return
+	}
+	if result.MatchedCount == 0 {
+		response.WriteError(w, r, response.NotFound("User not found", "No suspended user with the specified ID exists."))
+		return
+	}
+	h.audit.Record(r, audit.Event{
+		Action:   "user.reactivate",
+		Entity:   "users",
+		EntityID: userID.Hex(),
+		Before:   before,
+		After:    collection.Snapshot(ctx, bson.M{"_id": userID}),
+	})
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": "User reactivated successfully",
+	})
+}
+
+// AdminDeleteUser melakukan soft delete: akun ditandai terhapus dan tidak bisa login,
+// tetapi datanya (pesanan, ulasan) tetap tersimpan
+func (h *Handler) AdminDeleteUser(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	h.updateUserStatus(w, r, bson.M{
+		"status":     model.UserStatusDeleted,
+		"deleted_at": now,
+		"updated_at": now,
+	}, "user.delete", "User deleted successfully")
+}
+
+// checkTargetUser memastikan pemanggil boleh mengelola akun target: akun admin hanya dapat
+// dikelola admin, dan pemanggil harus memiliki setiap permission dari role target.
+func (h *Handler) checkTargetUser(r *http.Request, target model.Users) error {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		return response.Unauthorized("Unauthorized", "A valid bearer token is required.")
+	}
+	if principal.Role == model.RoleAdmin {
+		return nil
+	}
+	if target.Role == model.RoleAdmin {
+		return response.Forbidden("Access denied", "Only an admin can manage an admin account.")
+	}
+
+	role, err := h.repos.Roles.FindByName(r.Context(), target.Role)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	} else if err != nil {
+		return response.Internal("Failed to check permission", "An error occurred while checking your permissions.").WithCause(err)
+	}
+	for _, p := range role.Permissions {
+		allowed, err := h.guard.HasPermission(r.Context(), p)
+		if err != nil {
+			return response.Internal("Failed to check permission", "An error occurred while checking your permissions.").WithCause(err)
+		}
+		if !allowed {
+			return response.Forbidden("Access denied", "You cannot manage a user whose role has permissions you do not have.")
+		}
+	}
+	return nil
+}
+
+// updateUserStatus menerapkan perubahan status pada user di path {id} lalu mencabut sesinya.
+// Admin tidak dapat mengubah status akunnya sendiri, dan target harus lolos checkTargetUser.
+func (h *Handler) updateUserStatus(w http.ResponseWriter, r *http.Request, fields bson.M, action, message string) {
+	userID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid user ID", "User ID format is incorrect."))
+		return
+	}
+	if principal, ok := middleware.PrincipalFromContext(r.Context()); ok && principal.UserID == userID {
+		response.WriteError(w, r, response.Forbidden("Access denied", "You cannot change the status of your own account."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	collection := h.repos.Users
+	target, err := collection.FindByID(ctx, userID)
+	if err == mongo.ErrNoDocuments {
+		response.WriteError(w, r, response.NotFound("User not found", "The user with the specified ID does not exist."))
+		return
+	} else if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to update user", "An error occurred while updating the user's status.").WithCause(err))
+		return
+	}
+	if err := h.checkTargetUser(r, target); err != nil {
+		response.WriteError(w, r, err)
+		return
+	}
+
+	before := collection.Snapshot(ctx, bson.M{"_id": userID})
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": userID, "status": bson.M{"$ne": model.UserStatusDeleted}},
+		bson.M{"$set": fields},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to update user", "An error occurred while updating the user's status.").WithCause(err))
+		return
+	}
+	if result.MatchedCount == 0 {
+		response.WriteError(w, r, response.NotFound("User not found", "The user with the specified ID does not exist."))
+		return
+	}
+	h.audit.Record(r, audit.Event{
+		Action:   action,
+		Entity:   "users",
+		EntityID: userID.Hex(),
+		Before:   before,
+		After:    collection.Snapshot(ctx, bson.M{"_id": userID}),
+	})
+
+	if err := h.auth.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to revoke sessions", "The user was updated, but existing sessions could not be revoked.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": message,
+	})
+}
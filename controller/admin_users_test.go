@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"plastiqu_co/model"
+	"plastiqu_co/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// addUser menyimpan pengguna aktif dengan role tertentu dan mengembalikan ID-nya
+func addUser(t *testing.T, repos *repository.Repositories, username, role string) string {
+	t.Helper()
+	id := primitive.NewObjectID()
+	user := model.Users{ID: id, Username: username, Email: username + "@example.com", Role: role, Status: model.UserStatusActive}
+	if err := repos.Users.Insert(context.Background(), user); err != nil {
+		t.Fatalf("insert user %s: %v", username, err)
+	}
+	return id.Hex()
+}
+
+func TestManagingUsersRequiresTheTargetRolePermissions(t *testing.T) {
+	h, repos := newTestHandler(t)
+	addRole(t, repos, "user_manager", model.PermissionUsersManage, model.PermissionOrdersRead, model.PermissionReviewsRespond)
+	admin := addUser(t, repos, "root", model.RoleAdmin)
+	warehouse := addUser(t, repos, "gudang", model.RoleWarehouse)
+	support := addUser(t, repos, "cs", model.RoleCustomerService)
+
+	tests := []struct {
+		name   string
+		role   string
+		target string
+		status int
+	}{
+		{"admin account by a non-admin", "user_manager", admin, http.StatusForbidden},
+		{"role with a permission the caller lacks", "user_manager", warehouse, http.StatusForbidden},
+		{"role covered by the caller", "user_manager", support, http.StatusOK},
+		{"admin account by an admin", model.RoleAdmin, admin, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := map[string]string{"id": tt.target}
+			status, body := callAs(t, tt.role, h.AdminSuspendUser, http.MethodPost, "/admin/users/"+tt.target+"/suspend", vars, "")
+			if status != tt.status {
+				t.Fatalf("suspend: status %d, want %d (body %v)", status, tt.status, body)
+			}
+			status, body = callAs(t, tt.role, h.AdminReactivateUser, http.MethodPost, "/admin/users/"+tt.target+"/reactivate", vars, "")
+			if status != tt.status {
+				t.Fatalf("reactivate: status %d, want %d (body %v)", status, tt.status, body)
+			}
+			status, body = callAs(t, tt.role, h.AdminUpdateUserProfile, http.MethodPut, "/admin/update-user-profile?user_id="+tt.target, nil, `{"phone":"081234567890"}`)
+			if status != tt.status {
+				t.Fatalf("update profile: status %d, want %d (body %v)", status, tt.status, body)
+			}
+			status, body = callAs(t, tt.role, h.AdminDeleteUser, http.MethodDelete, "/admin/users/"+tt.target, vars, "")
+			if status != tt.status {
+				t.Fatalf("delete: status %d, want %d (body %v)", status, tt.status, body)
+			}
+		})
+	}
+
+	// Akun yang ditolak tetap aktif
+	id, _ := primitive.ObjectIDFromHex(warehouse)
+	user, err := repos.Users.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if user.Status != model.UserStatusActive {
+		t.Errorf("status = %q, want %q", user.Status, model.UserStatusActive)
+	}
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/metric"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
+	"plastiqu_co/middleware"
+	"plastiqu_co/model"
+	"plastiqu_co/repository"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// apiKeyScheme adalah awalan semua API key agar mudah dikenali, termasuk oleh secret scanner
+const apiKeyScheme = "plq_"
+
+// generateAPIKey membuat kunci berformat plq_<prefix>_<secret> beserta prefix-nya
+func generateAPIKey() (key, prefix string, err error) {
+	raw := make([]byte, 4)
+	if _, err = rand.Read(raw); err != nil {
+		return
+	}
+	secret, err := metric.GenerateOpaqueToken(32)
+	if err != nil {
+		return
+	}
+	prefix = apiKeyScheme + hex.EncodeToString(raw)
+	key = prefix + "_" + secret
+	return
+}
+
+// GetAPIKeys menampilkan semua API key tanpa rahasianya
+func (h *Handler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	keys, err := h.repos.APIKeys.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to retrieve API keys", "An error occurred while retrieving API keys.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, keys)
+}
+
+// userOnlyPermissions tidak dapat diberikan ke API key; route-nya hanya menerima sesi pengguna
+var userOnlyPermissions = map[string]bool{
+	model.PermissionUsersManage:   true,
+	model.PermissionRolesManage:   true,
+	model.PermissionAuditRead:     true,
+	model.PermissionAPIKeysManage: true,
+}
+
+// CreateAPIKeyRequest adalah body untuk membuat API key. ExpiresAt kosong berarti tidak kedaluwarsa.
+type CreateAPIKeyRequest struct {
+	Name        string     `json:"name" validate:"required,max=100"`
+	Permissions []string   `json:"permissions" validate:"required,min=1"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// CreateAPIKey membuat API key baru. Kunci lengkap hanya dikembalikan sekali pada respon ini.
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var request CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if err := validate.Struct(request); err != nil {
+		response.WriteError(w, r, err)
+		return
+	}
+	if p, ok := invalidPermission(request.Permissions); ok {
+		response.WriteError(w, r, response.Validation("Invalid permission", "Unknown permission: "+p))
+		return
+	}
+	for _, p := range request.Permissions {
+		if userOnlyPermissions[p] {
+			response.WriteError(w, r, response.Validation("Invalid permission", "API keys cannot be granted "+p+"."))
+			return
+		}
+		// Pembuat hanya dapat memberikan permission yang dimilikinya sendiri
+		allowed, err := h.guard.HasPermission(r.Context(), p)
+		if err != nil {
+			response.WriteError(w, r, response.Internal("Failed to check permission", "An error occurred while checking your permissions.").WithCause(err))
+			return
+		}
+		if !allowed {
+			response.WriteError(w, r, response.Forbidden("Access denied", "You cannot grant a permission you do not have: "+p+"."))
+			return
+		}
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		response.WriteError(w, r, response.Validation("Invalid expiry", "expires_at must be in the future."))
+		return
+	}
+
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.WriteError(w, r, response.Unauthorized("Unauthorized", "A valid bearer token is required."))
+		return
+	}
+
+	key, prefix, err := generateAPIKey()
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to create API key", "An error occurred while generating the API key.").WithCause(err))
+		return
+	}
+
+	apiKey := model.APIKey{
+		ID:          primitive.NewObjectID(),
+		Name:        request.Name,
+		Prefix:      prefix,
+		KeyHash:     metric.HashToken(key),
+		Permissions: request.Permissions,
+		CreatedBy:   principal.UserID,
+		ExpiresAt:   request.ExpiresAt,
+		CreatedAt:   time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.repos.APIKeys.Insert(ctx, apiKey); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to create API key", "An error occurred while creating the API key.").WithCause(err))
+		return
+	}
+	h.audit.Record(r, audit.Event{Action: "api_key.create", Entity: repository.APIKeyCollection, EntityID: apiKey.ID.Hex(), After: apiKey})
+
+	response.JSON(w, http.StatusCreated, map[string]interface{}{
+		"message": "API key created. Store the key now; it will not be shown again.",
+		"key":     key,
+		"api_key": apiKey,
+	})
+}
+
+// RevokeAPIKey mencabut API key sehingga tidak dapat dipakai lagi
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid API key ID", "API key ID format is incorrect."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	collection := h.repos.APIKeys
+	before := collection.Snapshot(ctx, bson.M{"_id": keyID})
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": keyID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to revoke API key", "An error occurred while revoking the API key.").WithCause(err))
+		return
+	}
+	if result.MatchedCount == 0 {
+		response.WriteError(w, r, response.NotFound("API key not found", "No active API key with the specified ID exists."))
+		return
+	}
+	h.audit.Record(r, audit.Event{
+		Action:   "api_key.revoke",
+		Entity:   repository.APIKeyCollection,
+		EntityID: keyID.Hex(),
+		Before:   before,
+		After:    collection.Snapshot(ctx, bson.M{"_id": keyID}),
+	})
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": "API key revoked successfully",
+	})
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"plastiqu_co/helper/response"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdminListAuditLog menampilkan jejak audit terbaru lebih dulu.
+// Query parameter: actor_id, action, entity, entity_id, from, to, page, limit.
+func (h *Handler) AdminListAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := bson.M{}
+
+	if actorID := query.Get("actor_id"); actorID != "" {
+		objID, err := primitive.ObjectIDFromHex(actorID)
+		if err != nil {
+			response.WriteError(w, r, response.BadRequest("Invalid actor ID", "Actor ID format is incorrect."))
+			return
+		}
+		filter["actor_id"] = objID
+	}
+	for _, field := range []string{"action", "entity", "entity_id"} {
+		if value := query.Get(field); value != "" {
+			filter[field] = value
+		}
+	}
+
+	from, err := parseDateParam(query.Get("from"), h.loc)
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid from", "Use RFC3339 or YYYY-MM-DD format."))
+		return
+	}
+	to, err := parseDateParam(query.Get("to"), h.loc)
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid to", "Use RFC3339 or YYYY-MM-DD format."))
+		return
+	}
+	if len(query.Get("to")) == len("2006-01-02") {
+		to = to.Add(24 * time.Hour)
+	}
+	if !from.IsZero() || !to.IsZero() {
+		createdAt := bson.M{}
+		if !from.IsZero() {
+			createdAt["$gte"] = from
+		}
+		if !to.IsZero() {
+			createdAt["$lt"] = to
+		}
+		filter["created_at"] = createdAt
+	}
+
+	page, limit := pagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	entries, total, err := h.repos.AuditLog.Find(ctx, filter, (page-1)*limit, limit)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to retrieve audit log", "An error occurred while retrieving the audit log.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"data":  entries,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+	})
+}
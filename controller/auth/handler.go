@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/mailer"
+	"plastiqu_co/middleware"
+	"plastiqu_co/repository"
+)
+
+// Mail berisi pengirim email dan alamat yang dipakai untuk membuat tautan di dalam email
+type Mail struct {
+	Sender      mailer.Sender
+	BaseURL     string // Alamat publik API, mis. untuk tautan verifikasi email
+	FrontendURL string // Alamat aplikasi web yang menyediakan halaman /reset-password
+}
+
+// Handler melayani endpoint autentikasi: registrasi, login, sesi, 2FA dan reset password
+type Handler struct {
+	repos         *repository.Repositories
+	guard         *middleware.Guard
+	audit         *audit.Recorder
+	mail          Mail
+	resetNotifier PasswordResetNotifier
+}
+
+// NewHandler membuat Handler di atas repository, guard, perekam audit dan pengirim email aplikasi
+func NewHandler(repos *repository.Repositories, guard *middleware.Guard, recorder *audit.Recorder, mail Mail) *Handler {
+	return &Handler{repos: repos, guard: guard, audit: recorder, mail: mail, resetNotifier: emailResetNotifier{mail: mail}}
+}
+
+// SetResetNotifier mengganti kanal pengiriman token reset password (mis. WhatsApp atau SMS).
+// Default-nya email lewat Mail.Sender.
+func (h *Handler) SetResetNotifier(notifier PasswordResetNotifier) {
+	h.resetNotifier = notifier
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"plastiqu_co/helper/atdb"
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/mailer"
+	"plastiqu_co/middleware"
+	"plastiqu_co/repository"
+)
+
+// outbox menyimpan email yang dikirim Handler selama pengujian
+type outbox struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (o *outbox) Send(ctx context.Context, msg mailer.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+// link mengembalikan tautan terakhir dengan awalan prefix yang dikirim ke alamat to
+func (o *outbox) link(t *testing.T, to, prefix string) *url.URL {
+	t.Helper()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	pattern := regexp.MustCompile(regexp.QuoteMeta(prefix) + `\S*`)
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if o.messages[i].To != to {
+			continue
+		}
+		if match := pattern.FindString(o.messages[i].Body); match != "" {
+			u, err := url.Parse(match)
+			if err != nil {
+				t.Fatalf("parse link %q: %v", match, err)
+			}
+			return u
+		}
+	}
+	t.Fatalf("no email to %s with a link starting with %s", to, prefix)
+	return nil
+}
+
+// newTestHandler membuat Handler di atas MemoryDatabase yang sudah dimigrasi
+func newTestHandler(t *testing.T) (*Handler, *repository.Repositories, *outbox) {
+	t.Helper()
+	repos := repository.New(atdb.NewMemoryDatabase())
+	if _, err := repos.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	mail := &outbox{}
+	h := NewHandler(repos, middleware.NewGuard(repos), audit.NewRecorder(repos.AuditLog), Mail{
+		Sender:      mail,
+		BaseURL:     "http://api.test",
+		FrontendURL: "http://app.test",
+	})
+	return h, repos, mail
+}
+
+// call menjalankan handler dengan body JSON dan mengembalikan status serta body respon
+func call(t *testing.T, handler http.HandlerFunc, method, target, body string) (int, map[string]interface{}) {
+	t.Helper()
+	return callWithToken(t, handler, method, target, "", body)
+}
+
+// callWithToken seperti call, dengan bearer token pada header Authorization jika token tidak kosong
+func callWithToken(t *testing.T, handler http.Handler, method, target, token, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("%s %s: decode response %q: %v", method, target, rec.Body.String(), err)
+	}
+	return rec.Code, decoded
+}
+
+const (
+	testEmail    = "ana@example.com"
+	testPassword = "Rahasia123!"
+)
+
+// login masuk dengan kredensial pengujian dan mengembalikan body respon sukses
+func login(t *testing.T, h *Handler) map[string]interface{} {
+	t.Helper()
+	status, body := call(t, h.LoginUsers, http.MethodPost, "/login", `{"email":"`+testEmail+`","password":"`+testPassword+`"}`)
+	if status != http.StatusOK {
+		t.Fatalf("login: status %d, body %v", status, body)
+	}
+	return body
+}
+
+func TestRegisterVerifyAndLogin(t *testing.T) {
+	h, _, mail := newTestHandler(t)
+
+	status, body := call(t, h.RegisterUsers, http.MethodPost, "/regis",
+		`{"username":"ana","email":"Ana@Example.com","phone":"081234567890","password":"`+testPassword+`"}`)
+	if status != http.StatusOK {
+		t.Fatalf("register: status %d, body %v", status, body)
+	}
+
+	// Email yang sama dengan huruf berbeda ditolak
+	status, _ = call(t, h.RegisterUsers, http.MethodPost, "/regis",
+		`{"username":"ana2","email":"ANA@example.com","phone":"081234567890","password":"`+testPassword+`"}`)
+	if status != http.StatusConflict {
+		t.Fatalf("duplicate register: status %d, want %d", status, http.StatusConflict)
+	}
+
+	// Login ditolak sebelum email diverifikasi
+	status, _ = call(t, h.LoginUsers, http.MethodPost, "/login", `{"email":"`+testEmail+`","password":"`+testPassword+`"}`)
+	if status != http.StatusForbidden {
+		t.Fatalf("login before verification: status %d, want %d", status, http.StatusForbidden)
+	}
+
+	link := mail.link(t, testEmail, "http://api.test/verify-email?")
+	status, body = call(t, h.VerifyEmail, http.MethodGet, "/verify-email?"+link.RawQuery, "")
+	if status != http.StatusOK {
+		t.Fatalf("verify email: status %d, body %v", status, body)
+	}
+	// Tautan verifikasi hanya berlaku sekali
+	status, _ = call(t, h.VerifyEmail, http.MethodGet, "/verify-email?"+link.RawQuery, "")
+	if status != http.StatusBadRequest {
+		t.Fatalf("reused verification link: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	body = login(t, h)
+	token, _ := body["token"].(string)
+	refreshToken, _ := body["refresh_token"].(string)
+	if token == "" || refreshToken == "" || body["role"] != "user" {
+		t.Fatalf("login response missing tokens or role: %v", body)
+	}
+}
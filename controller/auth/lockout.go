@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/logger"
+	"plastiqu_co/helper/response"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// loginAttemptWindow: kegagalan yang lebih lama dari ini tidak lagi dihitung
+	loginAttemptWindow = 15 * time.Minute
+	// loginFreeAttempts adalah jumlah kegagalan sebelum jeda progresif berlaku
+	loginFreeAttempts = 3
+	// loginMaxDelay membatasi jeda progresif antar percobaan
+	loginMaxDelay = time.Minute
+	// accountLockoutThreshold dan ipLockoutThreshold adalah jumlah kegagalan sebelum dikunci sementara
+	accountLockoutThreshold = 10
+	ipLockoutThreshold      = 50
+	// lockoutDuration adalah lama penguncian sementara
+	lockoutDuration = 15 * time.Minute
+)
+
+// dummyPasswordHash dipakai saat email tidak ditemukan agar waktu respon tetap seragam
+var dummyPasswordHash = []byte("$2a$10$3MfhCPXg8U1GNck3IOjniOTcG83.bHwHqGEbbbpYdP1n6SYDV3AFy")
+
+func accountAttemptKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// loginRetryAfter mengembalikan berapa lama pemanggil harus menunggu sebelum boleh mencoba login lagi
+// untuk key tertentu, baik karena penguncian maupun jeda progresif.
+func (h *Handler) loginRetryAfter(ctx context.Context, key string) (time.Duration, error) {
+	attempt, err := h.repos.LoginAttempts.FindByKey(ctx, key)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+		return attempt.LockedUntil.Sub(now), nil
+	}
+	if now.Sub(attempt.LastFailureAt) > loginAttemptWindow || attempt.Failures <= loginFreeAttempts {
+		return 0, nil
+	}
+
+	delay := progressiveDelay(attempt.Failures)
+	if wait := attempt.LastFailureAt.Add(delay).Sub(now); wait > 0 {
+		return wait, nil
+	}
+	return 0, nil
+}
+
+// progressiveDelay menggandakan jeda untuk setiap kegagalan setelah loginFreeAttempts
+func progressiveDelay(failures int) time.Duration {
+	exponent := failures - loginFreeAttempts - 1
+	if exponent > 6 {
+		return loginMaxDelay
+	}
+	delay := time.Second << uint(exponent)
+	if delay > loginMaxDelay {
+		return loginMaxDelay
+	}
+	return delay
+}
+
+// recordLoginFailure menambah hitungan kegagalan untuk key dan menguncinya bila mencapai threshold.
+// Hitungan dinaikkan secara atomik agar percobaan bersamaan tidak saling menimpa.
+func (h *Handler) recordLoginFailure(ctx context.Context, key string, threshold int) error {
+	now := time.Now()
+	attempt, err := h.repos.LoginAttempts.RecordFailure(ctx, key, now.Add(-loginAttemptWindow))
+	if err != nil {
+		return err
+	}
+	if attempt.Failures < threshold || (attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil)) {
+		return nil
+	}
+
+	_, err = h.repos.LoginAttempts.UpdateOne(ctx,
+		bson.M{"_id": attempt.ID},
+		bson.M{"$set": bson.M{"locked_until": now.Add(lockoutDuration)}},
+	)
+	return err
+}
+
+// clearLoginAttempts menghapus catatan kegagalan untuk key tertentu
+func (h *Handler) clearLoginAttempts(ctx context.Context, key string) error {
+	_, err := h.repos.LoginAttempts.DeleteOne(ctx, bson.M{"key": key})
+	return err
+}
+
+// logAttemptError mencatat error saat menyimpan atau menghapus hitungan login. Respon login
+// tidak diubah, tetapi kegagalan ini berarti pembatasan percobaan sedang tidak bekerja.
+func logAttemptError(r *http.Request, message string, err error) {
+	if err != nil {
+		logger.FromContext(r.Context()).Error(message, "error", err)
+	}
+}
+
+// writeTooManyAttempts mengirim respon 429 dengan header Retry-After
+func writeTooManyAttempts(w http.ResponseWriter, r *http.Request, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+	response.WriteError(w, r, response.TooManyRequests("Too many login attempts", "Too many failed login attempts. Please try again later."))
+}
+
+// AdminUnlockUser menghapus penguncian login untuk akun tertentu
+func (h *Handler) AdminUnlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid user ID", "User ID format is incorrect."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.repos.Users.FindByID(ctx, userID)
+	if err != nil {
+		response.WriteError(w, r, response.NotFound("User not found", "The user with the specified ID does not exist."))
+		return
+	}
+
+	for _, key := range []string{accountAttemptKey(user.Email), twoFactorAttemptKey(user.ID.Hex())} {
+		if err := h.clearLoginAttempts(ctx, key); err != nil {
+			response.WriteError(w, r, response.Internal("Failed to unlock account", "An error occurred while unlocking the account.").WithCause(err))
+			return
+		}
+	}
+	h.audit.Record(r, audit.Event{Action: "user.unlock", Entity: "users", EntityID: userID.Hex()})
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": "Account unlocked successfully",
+	})
+}
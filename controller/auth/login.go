@@ -3,86 +3,153 @@ package auth
 import (
 	"context"
 	"encoding/json"
-	"plastiqu_co/config"
+	"plastiqu_co/helper/metric"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/middleware"
 	"plastiqu_co/model"
 	"net/http"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func LoginUsers(w http.ResponseWriter, r *http.Request) {
-	var credentials model.Users
-	var responseMessage string
+// LoginRequest adalah body untuk login dengan email dan password
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
 
+func (h *Handler) LoginUsers(w http.ResponseWriter, r *http.Request) {
+	var credentials LoginRequest
 	// Decode the request body into the credentials struct
 	err := json.NewDecoder(r.Body).Decode(&credentials)
 	if err != nil {
-		responseMessage = "Invalid request payload"
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   responseMessage,
-			"message": "The JSON request body could not be decoded. Please check the structure of your request.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
 		return
 	}
 
+	credentials.Email = NormalizeEmail(credentials.Email)
+
 	// Check if email or password is empty
 	if credentials.Email == "" || credentials.Password == "" {
-		responseMessage = "Email and password are required"
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   responseMessage,
-			"message": "Please provide both email and password.",
-		})
+		response.WriteError(w, r, response.Validation("Email and password are required", "Please provide both email and password."))
 		return
 	}
 
 	// Setup a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	// Get the MongoDB collection
-	collection := config.Mongoconn.Collection("users")
+	// Reject early while the account or IP is locked out or inside its progressive delay
+	accountKey := accountAttemptKey(credentials.Email)
+	ipKey := ipAttemptKey(middleware.ClientIP(r))
+	for _, key := range []string{accountKey, ipKey} {
+		wait, err := h.loginRetryAfter(ctx, key)
+		if err != nil {
+			response.WriteError(w, r, response.Internal("Failed to login", "An error occurred while processing the login request.").WithCause(err))
+			return
+		}
+		if wait > 0 {
+			writeTooManyAttempts(w, r, wait)
+			return
+		}
+	}
 
-	// Find the user by email
-	var user model.Users
-	err = collection.FindOne(ctx, bson.M{"email": credentials.Email}).Decode(&user)
-	if err != nil {
-		responseMessage = "User not found"
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   responseMessage,
-			"message": "The email is not registered.",
-		})
+	// Find the user by email. Unknown emails and wrong passwords get the same
+	// response so that registered emails cannot be enumerated.
+	passwordHash := dummyPasswordHash
+	user, err := h.repos.Users.FindByEmail(ctx, credentials.Email)
+	// Error database bukan kegagalan login dan tidak dihitung untuk penguncian
+	if err != nil && err != mongo.ErrNoDocuments {
+		response.WriteError(w, r, response.Internal("Failed to login", "An error occurred while processing the login request.").WithCause(err))
 		return
 	}
+	// Akun yang sudah dihapus diperlakukan seperti email yang tidak terdaftar
+	found := err == nil && user.Status != model.UserStatusDeleted
+	if found {
+		passwordHash = []byte(user.Password)
+	}
 
 	// Compare the provided password with the hashed password stored in the database
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(credentials.Password))
+	err = bcrypt.CompareHashAndPassword(passwordHash, []byte(credentials.Password))
+	if !found || err != nil {
+		logAttemptError(r, "failed to record login failure", h.recordLoginFailure(ctx, accountKey, accountLockoutThreshold))
+		logAttemptError(r, "failed to record login failure", h.recordLoginFailure(ctx, ipKey, ipLockoutThreshold))
+
+		response.WriteError(w, r, response.Unauthorized("Invalid credentials", "The email or password you entered is incorrect."))
+		return
+	}
+	logAttemptError(r, "failed to clear login attempts", h.clearLoginAttempts(ctx, accountKey))
+
+	// Block accounts that have not verified their email yet
+	if user.Status == model.UserStatusPendingVerification {
+		response.WriteError(w, r, response.Forbidden("Email not verified", "Please verify your email address before logging in."))
+		return
+	}
+
+	// Block accounts suspended by an admin
+	if user.Status == model.UserStatusSuspended {
+		response.WriteError(w, r, response.Forbidden("Account suspended", "Your account has been suspended. Please contact support."))
+		return
+	}
+
+	// Accounts with TOTP enabled must complete the second step at /login/2fa
+	if user.TwoFactor != nil && user.TwoFactor.Enabled {
+		writeTwoFactorChallenge(w, r, user)
+		return
+	}
+
+	// Roles that mandate 2FA may only enroll until TOTP has been set up
+	required, err := h.guard.RoleRequiresTwoFactor(ctx, user.Role)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to login", "An error occurred while processing the login request.").WithCause(err))
+		return
+	}
+	if required {
+		writeTwoFactorEnrollment(w, r, user)
+		return
+	}
+
+	h.writeLoginSuccess(ctx, w, r, user)
+}
+
+// writeLoginSuccess starts a session and issues the access and refresh tokens for a fully authenticated user
+func (h *Handler) writeLoginSuccess(ctx context.Context, w http.ResponseWriter, r *http.Request, user model.Users) {
+	// Record the session with the caller's device and IP
+	session, err := h.createSession(ctx, r, user.ID)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to create session", "An error occurred while creating the login session.").WithCause(err))
+		return
+	}
+
+	// Generate PASETO access token for the authenticated user
+	token, payload, err := metric.EncodeToken(user.ID.Hex(), user.Role, session.ID.Hex(), metric.AccessTokenDuration)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to generate token", "An error occurred while generating the access token.").WithCause(err))
+		return
+	}
+
+	// The session ID doubles as the refresh token family for this login
+	refreshToken, refreshExpiresAt, err := h.issueRefreshToken(ctx, user.ID, session.ID)
 	if err != nil {
-		responseMessage = "Invalid password"
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   responseMessage,
-			"message": "The password you entered is incorrect.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to generate token", "An error occurred while generating the refresh token.").WithCause(err))
 		return
 	}
 
 	// Send the response with user details if login is successful
-	response := map[string]interface{}{
-		"message":  "Login successful",
-		"user_id":  user.ID.Hex(),
-		"email":    user.Email,
-		"username" : user.Username,
-		"role":     user.Role,
+	body := map[string]interface{}{
+		"message":            "Login successful",
+		"token":              token,
+		"token_type":         "Bearer",
+		"expires_at":         payload.ExpiresAt,
+		"refresh_token":      refreshToken,
+		"refresh_expires_at": refreshExpiresAt,
+		"user_id":            user.ID.Hex(),
+		"email":              user.Email,
+		"username":           user.Username,
+		"role":               user.Role,
+		"session_id":         session.ID.Hex(),
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	response.JSON(w, http.StatusOK, body)
 }
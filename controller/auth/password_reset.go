@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"plastiqu_co/helper/mailer"
+	"plastiqu_co/helper/metric"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// passwordResetTTL adalah masa berlaku token reset password
+	passwordResetTTL = time.Hour
+	// passwordResetInterval adalah jeda minimum antar permintaan reset untuk satu akun
+	passwordResetInterval = time.Minute
+)
+
+// PasswordResetNotifier menyampaikan token reset password kepada pengguna
+type PasswordResetNotifier interface {
+	NotifyPasswordReset(ctx context.Context, user model.Users, token string) error
+}
+
+// emailResetNotifier adalah PasswordResetNotifier bawaan yang mengirim token lewat email
+type emailResetNotifier struct {
+	mail Mail
+}
+
+func (n emailResetNotifier) NotifyPasswordReset(ctx context.Context, user model.Users, token string) error {
+	// Halaman frontend menampilkan form password baru lalu memanggil POST /password/reset
+	link := strings.TrimSuffix(n.mail.FrontendURL, "/") + "/reset-password?token=" + url.QueryEscape(token)
+	return n.mail.Sender.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password akun Plastiqu",
+		Body: fmt.Sprintf("Halo %s,\n\nKami menerima permintaan untuk mereset password Anda. Gunakan token berikut:\n%s\n\natau buka tautan:\n%s\n\nToken berlaku selama 1 jam. Abaikan email ini jika Anda tidak meminta reset password.\n",
+			user.Username, token, link),
+	})
+}
+
+// invalidatePasswordResets menandai semua token reset milik user yang belum terpakai sebagai terpakai
+func (h *Handler) invalidatePasswordResets(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := h.repos.PasswordResets.UpdateMany(ctx,
+		bson.M{"user_id": userID, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": time.Now()}},
+	)
+	return err
+}
+
+// ForgotPassword menerbitkan token reset password sekali pakai dan mengirimkannya lewat resetNotifier.
+// Respon selalu sama agar tidak membocorkan email mana yang terdaftar.
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var request EmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Email == "" {
+		response.WriteError(w, r, response.InvalidPayload("An email is required."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	accepted := map[string]string{
+		"message": "If the email is registered, password reset instructions have been sent.",
+	}
+
+	// Akun yang ditangguhkan atau dihapus tidak menerima token reset
+	user, err := h.repos.Users.FindByEmail(ctx, NormalizeEmail(request.Email))
+	if err != nil || user.Status == model.UserStatusSuspended || user.Status == model.UserStatusDeleted {
+		response.JSON(w, http.StatusAccepted, accepted)
+		return
+	}
+
+	// Abaikan permintaan beruntun untuk akun yang sama tanpa memberi tahu pemanggil
+	recent, err := h.repos.PasswordResets.Count(ctx, bson.M{
+		"user_id":    user.ID,
+		"created_at": bson.M{"$gte": time.Now().Add(-passwordResetInterval)},
+	})
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to process request", "An error occurred while processing the password reset request.").WithCause(err))
+		return
+	}
+	if recent > 0 {
+		response.JSON(w, http.StatusAccepted, accepted)
+		return
+	}
+
+	token, err := metric.GenerateOpaqueToken(32)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to process request", "An error occurred while processing the password reset request.").WithCause(err))
+		return
+	}
+
+	// Hanya token terbaru yang berlaku
+	if err := h.invalidatePasswordResets(ctx, user.ID); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to process request", "An error occurred while processing the password reset request.").WithCause(err))
+		return
+	}
+
+	now := time.Now()
+	err = h.repos.PasswordResets.Insert(ctx, model.PasswordReset{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		TokenHash: metric.HashToken(token),
+		ExpiresAt: now.Add(passwordResetTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to process request", "An error occurred while processing the password reset request.").WithCause(err))
+		return
+	}
+
+	if err := h.resetNotifier.NotifyPasswordReset(ctx, user, token); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to send reset instructions", "An error occurred while sending the password reset instructions.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, accepted)
+}
+
+// ResetPasswordRequest adalah body untuk mengganti password memakai token reset
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+// ResetPassword mengganti password memakai token reset yang valid lalu mencabut seluruh sesi pengguna
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var request ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.WriteError(w, r, response.InvalidPayload("A reset token and new password are required."))
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		response.WriteError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	reset, err := h.repos.PasswordResets.FindByHash(ctx, metric.HashToken(request.Token))
+	if err != nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		response.WriteError(w, r, response.Validation("Invalid reset token", "The reset token is invalid, has expired or has already been used."))
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to hash password", "An error occurred while hashing the password.").WithCause(err))
+		return
+	}
+
+	// Token diklaim lebih dulu; filter used_at memastikan hanya satu request yang lolos
+	// jika token yang sama dipakai bersamaan
+	now := time.Now()
+	claim, err := h.repos.PasswordResets.UpdateOne(ctx,
+		bson.M{"_id": reset.ID, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": now}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to reset password", "An error occurred while validating the reset token.").WithCause(err))
+		return
+	}
+	if claim.MatchedCount == 0 {
+		response.WriteError(w, r, response.Validation("Invalid reset token", "The reset token is invalid, has expired or has already been used."))
+		return
+	}
+
+	// Akun yang ditangguhkan atau dihapus tidak dapat direset
+	result, err := h.repos.Users.UpdateOne(ctx,
+		bson.M{"_id": reset.UserID, "status": bson.M{"$nin": bson.A{model.UserStatusSuspended, model.UserStatusDeleted}}},
+		bson.M{"$set": bson.M{"password": string(hashedPassword), "updated_at": now}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to reset password", "An error occurred while updating the password.").WithCause(err))
+		return
+	}
+	if result.MatchedCount == 0 {
+		response.WriteError(w, r, response.Validation("Invalid reset token", "The reset token is invalid, has expired or has already been used."))
+		return
+	}
+
+	// Password lama mungkin bocor: cabut semua sesi dan token reset lain milik user
+	if err := h.RevokeUserRefreshTokens(ctx, reset.UserID); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to revoke sessions", "The password was changed, but existing sessions could not be revoked.").WithCause(err))
+		return
+	}
+	h.invalidatePasswordResets(ctx, reset.UserID)
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": "Password reset successfully. Please log in with your new password.",
+	})
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"plastiqu_co/helper/metric"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshRequest adalah body untuk menukar atau mencabut refresh token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// issueRefreshToken membuat refresh token baru dalam family yang diberikan dan
+// menyimpan hash-nya. Token mentah hanya dikembalikan sekali ke pemanggil.
+func (h *Handler) issueRefreshToken(ctx context.Context, userID, familyID primitive.ObjectID) (token string, expiresAt time.Time, err error) {
+	token, err = metric.GenerateOpaqueToken(32)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	expiresAt = now.Add(metric.RefreshTokenDuration)
+	err = h.repos.RefreshTokens.Insert(ctx, model.RefreshToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: metric.HashToken(token),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	})
+	return
+}
+
+// revokeTokenFamily mencabut seluruh refresh token dalam satu family beserta sesinya
+func (h *Handler) revokeTokenFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	if err := h.repos.RefreshTokens.Revoke(ctx, bson.M{"family_id": familyID}); err != nil {
+		return err
+	}
+	return h.repos.Sessions.End(ctx, bson.M{"_id": familyID})
+}
+
+// RevokeUserRefreshTokens mencabut seluruh refresh token dan sesi milik user
+func (h *Handler) RevokeUserRefreshTokens(ctx context.Context, userID primitive.ObjectID) error {
+	if err := h.repos.RefreshTokens.Revoke(ctx, bson.M{"user_id": userID}); err != nil {
+		return err
+	}
+	return h.repos.Sessions.End(ctx, bson.M{"user_id": userID})
+}
+
+// RefreshToken menukar refresh token yang valid dengan access token dan refresh token baru.
+// Pemakaian ulang refresh token yang sudah dirotasi mencabut seluruh family token tersebut,
+// begitu pula refresh oleh pengguna yang role-nya mewajibkan 2FA tetapi belum mendaftar.
+func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var request RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.RefreshToken == "" {
+		response.WriteError(w, r, response.InvalidPayload("A refresh_token is required."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	stored, err := h.repos.RefreshTokens.FindByHash(ctx, metric.HashToken(request.RefreshToken))
+	if err != nil {
+		response.WriteError(w, r, response.Unauthorized("Invalid refresh token", "The refresh token is not recognized."))
+		return
+	}
+
+	if stored.RevokedAt != nil || stored.RotatedAt != nil {
+		// Token lama dipakai lagi: anggap bocor dan cabut seluruh family
+		h.revokeTokenFamily(ctx, stored.FamilyID)
+		response.WriteError(w, r, response.Unauthorized("Invalid refresh token", "The refresh token has already been used or revoked."))
+		return
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		response.WriteError(w, r, response.Unauthorized("Invalid refresh token", "The refresh token has expired."))
+		return
+	}
+
+	user, err := h.repos.Users.FindByID(ctx, stored.UserID)
+	if err != nil {
+		h.revokeTokenFamily(ctx, stored.FamilyID)
+		response.WriteError(w, r, response.Unauthorized("Invalid refresh token", "The user for this refresh token no longer exists."))
+		return
+	}
+	if user.Status == model.UserStatusSuspended || user.Status == model.UserStatusDeleted {
+		h.revokeTokenFamily(ctx, stored.FamilyID)
+		response.WriteError(w, r, response.Unauthorized("Invalid refresh token", "The account for this refresh token is no longer active."))
+		return
+	}
+	// Role yang kini mewajibkan 2FA: pengguna harus login ulang dan mendaftarkan 2FA
+	required, err := h.guard.RoleRequiresTwoFactor(ctx, user.Role)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to refresh token", "An error occurred while checking the two-factor policy.").WithCause(err))
+		return
+	}
+	if required && (user.TwoFactor == nil || !user.TwoFactor.Enabled) {
+		h.revokeTokenFamily(ctx, stored.FamilyID)
+		response.WriteError(w, r, response.Unauthorized("Invalid refresh token", "Your role requires two-factor authentication. Please log in again to enroll."))
+		return
+	}
+
+	// Tandai token sebagai sudah dirotasi; filter rotated_at mencegah dua request memakai token yang sama
+	result, err := h.repos.RefreshTokens.UpdateOne(ctx,
+		bson.M{"_id": stored.ID, "rotated_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"rotated_at": time.Now()}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to refresh token", "An error occurred while rotating the refresh token.").WithCause(err))
+		return
+	}
+	if result.ModifiedCount == 0 {
+		h.revokeTokenFamily(ctx, stored.FamilyID)
+		response.WriteError(w, r, response.Unauthorized("Invalid refresh token", "The refresh token has already been used or revoked."))
+		return
+	}
+
+	refreshToken, refreshExpiresAt, err := h.issueRefreshToken(ctx, user.ID, stored.FamilyID)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to refresh token", "An error occurred while issuing a new refresh token.").WithCause(err))
+		return
+	}
+
+	if err := h.touchSession(ctx, r, user.ID, stored.FamilyID, refreshExpiresAt); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to refresh token", "An error occurred while updating the session.").WithCause(err))
+		return
+	}
+
+	token, payload, err := metric.EncodeToken(user.ID.Hex(), user.Role, stored.FamilyID.Hex(), metric.AccessTokenDuration)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to generate token", "An error occurred while generating the access token.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":            "Token refreshed successfully",
+		"token":              token,
+		"token_type":         "Bearer",
+		"expires_at":         payload.ExpiresAt,
+		"refresh_token":      refreshToken,
+		"refresh_expires_at": refreshExpiresAt,
+	})
+}
+
+// Logout mencabut refresh token beserta seluruh family-nya
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	var request RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.RefreshToken == "" {
+		response.WriteError(w, r, response.InvalidPayload("A refresh_token is required."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	stored, err := h.repos.RefreshTokens.FindByHash(ctx, metric.HashToken(request.RefreshToken))
+	if err == nil {
+		if err = h.revokeTokenFamily(ctx, stored.FamilyID); err != nil {
+			response.WriteError(w, r, response.Internal("Failed to logout", "An error occurred while revoking the refresh token.").WithCause(err))
+			return
+		}
+	}
+
+	// Token yang tidak dikenal tetap dianggap berhasil logout agar endpoint ini idempotent
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": "Logged out successfully",
+	})
+}
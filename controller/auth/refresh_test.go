@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// registerVerified mendaftarkan pengguna pengujian lalu memverifikasi email-nya lewat tautan di outbox
+func registerVerified(t *testing.T, h *Handler, mail *outbox) {
+	t.Helper()
+	status, body := call(t, h.RegisterUsers, http.MethodPost, "/regis",
+		`{"username":"ana","email":"`+testEmail+`","phone":"081234567890","password":"`+testPassword+`"}`)
+	if status != http.StatusOK {
+		t.Fatalf("register: status %d, body %v", status, body)
+	}
+	link := mail.link(t, testEmail, "http://api.test/verify-email?")
+	if status, body := call(t, h.VerifyEmail, http.MethodGet, "/verify-email?"+link.RawQuery, ""); status != http.StatusOK {
+		t.Fatalf("verify email: status %d, body %v", status, body)
+	}
+}
+
+// refresh menukar refresh token dan mengembalikan status beserta refresh token baru
+func refresh(t *testing.T, h *Handler, token string) (int, string) {
+	t.Helper()
+	status, body := call(t, h.RefreshToken, http.MethodPost, "/auth/refresh", `{"refresh_token":"`+token+`"}`)
+	next, _ := body["refresh_token"].(string)
+	return status, next
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	h, repos, mail := newTestHandler(t)
+	registerVerified(t, h, mail)
+
+	// Dua login berarti dua sesi, masing-masing dengan family refresh token sendiri
+	first := login(t, h)
+	other := login(t, h)
+
+	original := first["refresh_token"].(string)
+	status, rotated := refresh(t, h, original)
+	if status != http.StatusOK || rotated == "" {
+		t.Fatalf("first refresh: status %d", status)
+	}
+
+	// Token yang sudah dirotasi dipakai lagi: ditolak dan seluruh family dicabut
+	if status, _ := refresh(t, h, original); status != http.StatusUnauthorized {
+		t.Fatalf("reused refresh token: status %d, want %d", status, http.StatusUnauthorized)
+	}
+	if status, _ := refresh(t, h, rotated); status != http.StatusUnauthorized {
+		t.Fatalf("refresh token rotated from a reused one: status %d, want %d", status, http.StatusUnauthorized)
+	}
+
+	userID, _ := primitive.ObjectIDFromHex(first["user_id"].(string))
+	sessionID, _ := primitive.ObjectIDFromHex(first["session_id"].(string))
+	active, err := repos.Sessions.IsActive(context.Background(), sessionID, userID)
+	if err != nil {
+		t.Fatalf("check session: %v", err)
+	}
+	if active {
+		t.Error("session of the revoked family is still active")
+	}
+
+	// Sesi lain milik pengguna yang sama tidak terpengaruh
+	if status, _ := refresh(t, h, other["refresh_token"].(string)); status != http.StatusOK {
+		t.Fatalf("refresh in another session: status %d, want %d", status, http.StatusOK)
+	}
+}
@@ -2,128 +2,97 @@ package auth
 
 import (
 	"encoding/json"
-	"plastiqu_co/config"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
 	"plastiqu_co/model"
 	"net/http"
+	"strings"
 	"time"
 
 	"context"
 
-	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func RegisterUsers(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) RegisterUsers(w http.ResponseWriter, r *http.Request) {
 	var user model.Users
-	var responseMessage string
-
 	// Decode the JSON request body into the user struct
 err := json.NewDecoder(r.Body).Decode(&user)
 if err != nil {
-    responseMessage = "Invalid request payload"
-    w.Header().Set("Content-Type", "application/json")
-    w.WriteHeader(http.StatusBadRequest)
-    json.NewEncoder(w).Encode(map[string]string{
-        "error":   responseMessage,
-        "message": "The JSON request body could not be decoded. Please check the structure of your request.",
-    })
+    response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
     return
 }
 
-	// Validasi masing-masing field
-	if user.Username == "" {
-		responseMessage = "First name is required"
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   responseMessage,
-			"message": "Please provide a valid first name.",
-		})
-		return
-	}
+	// Normalisasi email dan username sebelum divalidasi dan disimpan
+	user.Email = NormalizeEmail(user.Email)
+	user.Username = strings.TrimSpace(user.Username)
 
-	if user.Phone == "" {
-		responseMessage = "Phone number is required"
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   responseMessage,
-			"message": "Please provide a valid phone number.",
-		})
+	// Validasi seluruh field sekaligus berdasarkan tag pada model.Users
+	if err := validate.Struct(user); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
-	if user.Email == "" {
-		responseMessage = "Email is required"
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   responseMessage,
-			"message": "Please provide a valid email address.",
-		})
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	// Tolak email atau username yang sudah terdaftar
+	conflicts, err := h.UserConflicts(ctx, user.Email, user.Username, nil)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to check existing users", "An error occurred while checking for existing accounts.").WithCause(err))
 		return
 	}
-
-	if user.Password == "" {
-		responseMessage = "Password is required"
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   responseMessage,
-			"message": "Please provide a password.",
-		})
+	if len(conflicts) > 0 {
+		response.WriteError(w, r, ConflictError(conflicts))
 		return
 	}
-	
 
 	// Hash the user's password before saving it to the database
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
-		responseMessage = "Failed to hash password"
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   responseMessage,
-			"message": "An error occurred while hashing the password.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to hash password", "An error occurred while hashing the password.").WithCause(err))
 		return
 	}
 	user.Password = string(hashedPassword)
 
-	user.Role = "user" // Set default role to "user"
+	user.Role = model.RoleUser // Set default role to "user"
+	user.Status = model.UserStatusPendingVerification // Akun aktif setelah email diverifikasi
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
-	collection := config.Mongoconn.Collection("users")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	result, err := collection.InsertOne(ctx, bson.M{
-		"username": user.Username,
-		"phone":      user.Phone,
-		"email":      user.Email,
-		"role":       user.Role,
-		"password":   user.Password,
-		"created_at": user.CreatedAt,
-		"updated_at": user.UpdatedAt,
+	user.ID = primitive.NewObjectID()
+	err = h.repos.Users.Insert(ctx, model.Users{
+		ID:        user.ID,
+		Username:  user.Username,
+		Phone:     user.Phone,
+		Email:     user.Email,
+		Role:      user.Role,
+		Status:    user.Status,
+		Password:  user.Password,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
 	})
 
+	if fields, ok := DuplicateKeyConflicts(err); ok {
+		// Pendaftaran bersamaan lolos pengecekan awal tapi ditolak oleh index unik
+		response.WriteError(w, r, ConflictError(fields))
+		return
+	}
 	if err != nil {
-		responseMessage = "Failed to insert user"
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   responseMessage,
-			"message": "An error occurred while inserting the user into the database.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to insert user", "An error occurred while inserting the user into the database.").WithCause(err))
 		return
 	}
 
-	response := map[string]interface{}{
-		"message": "User registered successfully",
-		"user_id": result.InsertedID,
+	// Send the one-time verification link to the registered email
+	message := "User registered successfully. Please check your email to verify your account."
+	if err := h.sendVerificationEmail(ctx, user); err != nil {
+		message = "User registered successfully, but the verification email could not be sent. Please request a new one."
+	}
+
+	body := map[string]interface{}{
+		"message": message,
+		"user_id": user.ID,
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	response.JSON(w, http.StatusOK, body)
 }
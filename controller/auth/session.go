@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"plastiqu_co/helper/metric"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/middleware"
+	"plastiqu_co/model"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// createSession mencatat sesi login baru. ID sesi dipakai sebagai family refresh token
+// dan disisipkan ke access token sebagai klaim "sid".
+func (h *Handler) createSession(ctx context.Context, r *http.Request, userID primitive.ObjectID) (model.Session, error) {
+	now := time.Now()
+	session := model.Session{
+		ID:         primitive.NewObjectID(),
+		UserID:     userID,
+		IP:         middleware.ClientIP(r),
+		UserAgent:  r.UserAgent(),
+		Date:       now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(metric.RefreshTokenDuration),
+	}
+	err := h.repos.Sessions.Insert(ctx, session)
+	return session, err
+}
+
+// touchSession memperpanjang sesi setelah refresh token dirotasi. Family lama yang dibuat
+// sebelum ada pencatatan sesi otomatis mendapat dokumen sesi baru.
+func (h *Handler) touchSession(ctx context.Context, r *http.Request, userID, sessionID primitive.ObjectID, expiresAt time.Time) error {
+	now := time.Now()
+	_, err := h.repos.Sessions.UpdateOne(ctx,
+		bson.M{"_id": sessionID},
+		bson.M{
+			"$set": bson.M{"last_seen_at": now, "expires_at": expiresAt},
+			"$setOnInsert": bson.M{
+				"user_id":    userID,
+				"ip":         middleware.ClientIP(r),
+				"user_agent": r.UserAgent(),
+				"duration":   0,
+				"date":       now,
+			},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// ListSessions menampilkan sesi aktif milik pengguna yang sedang login
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.WriteError(w, r, response.Unauthorized("Unauthorized", "A valid bearer token is required."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	sessions, err := h.repos.Sessions.Find(ctx,
+		bson.M{
+			"user_id":    principal.UserID,
+			"revoked_at": bson.M{"$exists": false},
+			"expires_at": bson.M{"$gt": time.Now()},
+		},
+		options.Find().SetSort(bson.M{"last_seen_at": -1}),
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to retrieve sessions", "An error occurred while retrieving sessions.").WithCause(err))
+		return
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == principal.SessionID
+	}
+
+	response.JSON(w, http.StatusOK, sessions)
+}
+
+// RevokeSession mengakhiri satu sesi milik pengguna beserta refresh token-nya
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.WriteError(w, r, response.Unauthorized("Unauthorized", "A valid bearer token is required."))
+		return
+	}
+	sessionID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid session ID", "Session ID format is incorrect."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	count, err := h.repos.Sessions.Count(ctx,
+		bson.M{"_id": sessionID, "user_id": principal.UserID, "revoked_at": bson.M{"$exists": false}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to revoke session", "An error occurred while revoking the session.").WithCause(err))
+		return
+	}
+	if count == 0 {
+		response.WriteError(w, r, response.NotFound("Session not found", "No active session with the specified ID exists."))
+		return
+	}
+
+	if err := h.revokeTokenFamily(ctx, sessionID); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to revoke session", "An error occurred while revoking the session.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions mengakhiri semua sesi pengguna kecuali sesi yang sedang dipakai
+func (h *Handler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.WriteError(w, r, response.Unauthorized("Unauthorized", "A valid bearer token is required."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	err := h.repos.RefreshTokens.Revoke(ctx, bson.M{
+		"user_id":   principal.UserID,
+		"family_id": bson.M{"$ne": principal.SessionID},
+	})
+	if err == nil {
+		err = h.repos.Sessions.End(ctx, bson.M{"user_id": principal.UserID, "_id": bson.M{"$ne": principal.SessionID}})
+	}
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to revoke sessions", "An error occurred while revoking the other sessions.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": "All other sessions have been signed out",
+	})
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"plastiqu_co/model"
+
+	"github.com/gorilla/mux"
+)
+
+// sessionRouter memasang endpoint sesi seperti pada routes, di belakang Authenticate
+func sessionRouter(h *Handler) *mux.Router {
+	router := mux.NewRouter()
+	router.Handle("/user/sessions", h.guard.Authenticate(http.HandlerFunc(h.ListSessions))).Methods("GET")
+	router.Handle("/user/sessions", h.guard.Authenticate(http.HandlerFunc(h.RevokeOtherSessions))).Methods("DELETE")
+	router.Handle("/user/sessions/{id}", h.guard.Authenticate(http.HandlerFunc(h.RevokeSession))).Methods("DELETE")
+	return router
+}
+
+// listSessions mengambil sesi aktif dengan access token yang diberikan
+func listSessions(t *testing.T, router http.Handler, token string) (int, []model.Session) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/user/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	var sessions []model.Session
+	if err := json.Unmarshal(rec.Body.Bytes(), &sessions); err != nil {
+		t.Fatalf("decode sessions %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, sessions
+}
+
+func TestSessions(t *testing.T) {
+	h, _, mail := newTestHandler(t)
+	registerVerified(t, h, mail)
+	router := sessionRouter(h)
+
+	// Tiga login berarti tiga sesi aktif
+	current, second, third := login(t, h), login(t, h), login(t, h)
+	token := current["token"].(string)
+
+	status, sessions := listSessions(t, router, token)
+	if status != http.StatusOK || len(sessions) != 3 {
+		t.Fatalf("list sessions: status %d, %d sessions, want 3", status, len(sessions))
+	}
+	for _, session := range sessions {
+		if want := session.ID.Hex() == current["session_id"]; session.Current != want {
+			t.Errorf("session %s current = %v, want %v", session.ID.Hex(), session.Current, want)
+		}
+	}
+
+	// Mencabut satu sesi membuat access token dan refresh token sesi itu tidak berlaku
+	secondID := second["session_id"].(string)
+	if status, body := callWithToken(t, router, http.MethodDelete, "/user/sessions/"+secondID, token, ""); status != http.StatusOK {
+		t.Fatalf("revoke session: status %d, body %v", status, body)
+	}
+	if status, _ := listSessions(t, router, second["token"].(string)); status != http.StatusUnauthorized {
+		t.Errorf("access token of a revoked session: status %d, want %d", status, http.StatusUnauthorized)
+	}
+	if status, _ := refresh(t, h, second["refresh_token"].(string)); status != http.StatusUnauthorized {
+		t.Errorf("refresh token of a revoked session: status %d, want %d", status, http.StatusUnauthorized)
+	}
+	if status, _ := callWithToken(t, router, http.MethodDelete, "/user/sessions/"+secondID, token, ""); status != http.StatusNotFound {
+		t.Errorf("revoke an already revoked session: status %d, want %d", status, http.StatusNotFound)
+	}
+
+	// Keluar dari perangkat lain mempertahankan sesi yang sedang dipakai
+	if status, body := callWithToken(t, router, http.MethodDelete, "/user/sessions", token, ""); status != http.StatusOK {
+		t.Fatalf("revoke other sessions: status %d, body %v", status, body)
+	}
+	if status, _ := listSessions(t, router, third["token"].(string)); status != http.StatusUnauthorized {
+		t.Errorf("access token of another session: status %d, want %d", status, http.StatusUnauthorized)
+	}
+	status, sessions = listSessions(t, router, token)
+	if status != http.StatusOK || len(sessions) != 1 || !sessions[0].Current {
+		t.Fatalf("list after revoking other sessions: status %d, sessions %+v, want only the current one", status, sessions)
+	}
+	if status, _ := refresh(t, h, current["refresh_token"].(string)); status != http.StatusOK {
+		t.Errorf("refresh in the current session: status %d, want %d", status, http.StatusOK)
+	}
+}
@@ -0,0 +1,450 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/metric"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/totp"
+	"plastiqu_co/middleware"
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// totpIssuer ditampilkan di aplikasi authenticator
+	totpIssuer = "Plastiqu"
+	// recoveryCodeCount adalah jumlah kode pemulihan yang dibuat sekaligus
+	recoveryCodeCount = 10
+)
+
+// TwoFactorRequest adalah body untuk mengaktifkan, mematikan, atau memakai 2FA.
+// Password hanya dibutuhkan saat mematikan 2FA.
+type TwoFactorRequest struct {
+	Code     string `json:"code"`
+	Password string `json:"password"`
+}
+
+// TwoFactorLoginRequest adalah body untuk menyelesaikan login dua langkah
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+func twoFactorAttemptKey(userID string) string {
+	return "2fa:" + userID
+}
+
+// writeTwoFactorChallenge menjawab login yang password-nya benar dengan token tantangan.
+// Login diselesaikan lewat LoginTwoFactor dengan kode TOTP atau kode pemulihan.
+func writeTwoFactorChallenge(w http.ResponseWriter, r *http.Request, user model.Users) {
+	token, payload, err := metric.EncodePurposeToken(user.ID.Hex(), user.Role, metric.PurposeTwoFactorChallenge, metric.ChallengeTokenDuration)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to generate token", "An error occurred while generating the challenge token.").WithCause(err))
+		return
+	}
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":             "Two-factor authentication required",
+		"two_factor_required": true,
+		"challenge_token":     token,
+		"expires_at":          payload.ExpiresAt,
+	})
+}
+
+// writeTwoFactorEnrollment menjawab login pengguna yang role-nya mewajibkan 2FA tetapi belum
+// mendaftar. Token yang diberikan hanya dapat dipakai untuk endpoint pendaftaran 2FA.
+func writeTwoFactorEnrollment(w http.ResponseWriter, r *http.Request, user model.Users) {
+	token, payload, err := metric.EncodePurposeToken(user.ID.Hex(), user.Role, metric.PurposeTwoFactorEnrollment, metric.AccessTokenDuration)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to generate token", "An error occurred while generating the enrollment token.").WithCause(err))
+		return
+	}
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":                        "Two-factor enrollment required",
+		"two_factor_enrollment_required": true,
+		"enrollment_token":               token,
+		"expires_at":                     payload.ExpiresAt,
+	})
+}
+
+// principalUser mengambil data user milik principal pada request
+func (h *Handler) principalUser(ctx context.Context, w http.ResponseWriter, r *http.Request) (model.Users, bool) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.WriteError(w, r, response.Unauthorized("Unauthorized", "A valid bearer token is required."))
+		return model.Users{}, false
+	}
+	user, err := h.repos.Users.FindByID(ctx, principal.UserID)
+	if err != nil {
+		response.WriteError(w, r, response.NotFound("User not found", "The user with the specified ID does not exist."))
+		return user, false
+	}
+	return user, true
+}
+
+// generateRecoveryCodes membuat kode pemulihan baru. Kode mentah hanya ditampilkan sekali,
+// yang disimpan adalah hash-nya.
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 7)
+		if _, err = rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(raw))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, metric.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+// verifyTOTP memvalidasi kode TOTP dan mencatat langkah waktunya sehingga kode yang sama
+// tidak dapat dipakai dua kali
+func (h *Handler) verifyTOTP(ctx context.Context, user model.Users, code string) (bool, error) {
+	if user.TwoFactor == nil || !user.TwoFactor.Enabled {
+		return false, nil
+	}
+	step, ok := totp.Validate(user.TwoFactor.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	result, err := h.repos.Users.UpdateOne(ctx,
+		bson.M{"_id": user.ID, "two_factor.last_step": bson.M{"$lt": step}},
+		bson.M{"$set": bson.M{"two_factor.last_step": step}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// verifySecondFactor menerima kode TOTP atau kode pemulihan. Kode pemulihan langsung hangus setelah dipakai.
+func (h *Handler) verifySecondFactor(ctx context.Context, user model.Users, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return h.verifyTOTP(ctx, user, code)
+	}
+	if user.TwoFactor == nil || !user.TwoFactor.Enabled {
+		return false, nil
+	}
+
+	hash := metric.HashToken(normalizeRecoveryCode(code))
+	result, err := h.repos.Users.UpdateOne(ctx,
+		bson.M{"_id": user.ID, "two_factor.recovery_codes": hash},
+		bson.M{"$pull": bson.M{"two_factor.recovery_codes": hash}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// SetupTwoFactor membuat secret TOTP baru untuk dipindai aplikasi authenticator.
+// 2FA belum aktif sampai dikonfirmasi dengan EnableTwoFactor.
+func (h *Handler) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.principalUser(ctx, w, r)
+	if !ok {
+		return
+	}
+	if user.TwoFactor != nil && user.TwoFactor.Enabled {
+		response.WriteError(w, r, response.Conflict("Two-factor already enabled", "Disable two-factor authentication before setting it up again."))
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to set up two-factor", "An error occurred while generating the secret.").WithCause(err))
+		return
+	}
+	_, err = h.repos.Users.UpdateOne(ctx,
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"two_factor.pending_secret": secret, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to set up two-factor", "An error occurred while saving the secret.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message":     "Scan the QR code with your authenticator app, then confirm with a code.",
+		"secret":      secret,
+		"otpauth_uri": totp.ProvisioningURI(totpIssuer, user.Email, secret),
+	})
+}
+
+// EnableTwoFactor mengaktifkan 2FA setelah pengguna membuktikan aplikasi authenticator-nya
+// menghasilkan kode yang benar, lalu mengembalikan kode pemulihan sekali saja
+func (h *Handler) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var request TwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Code == "" {
+		response.WriteError(w, r, response.InvalidPayload("A code is required."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.principalUser(ctx, w, r)
+	if !ok {
+		return
+	}
+	if user.TwoFactor != nil && user.TwoFactor.Enabled {
+		response.WriteError(w, r, response.Conflict("Two-factor already enabled", "Two-factor authentication is already enabled."))
+		return
+	}
+	if user.TwoFactor == nil || user.TwoFactor.PendingSecret == "" {
+		response.WriteError(w, r, response.Validation("Two-factor not set up", "Start the two-factor setup before enabling it."))
+		return
+	}
+
+	secret := user.TwoFactor.PendingSecret
+	step, valid := totp.Validate(secret, request.Code, time.Now())
+	if !valid {
+		response.WriteError(w, r, response.Validation("Invalid code", "The code is incorrect or has expired."))
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to enable two-factor", "An error occurred while generating recovery codes.").WithCause(err))
+		return
+	}
+
+	now := time.Now()
+	result, err := h.repos.Users.UpdateOne(ctx,
+		bson.M{"_id": user.ID, "two_factor.pending_secret": secret},
+		bson.M{"$set": bson.M{
+			"two_factor": model.TwoFactor{
+				Enabled:       true,
+				Secret:        secret,
+				RecoveryCodes: hashes,
+				LastStep:      step,
+				EnabledAt:     &now,
+			},
+			"updated_at": now,
+		}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to enable two-factor", "An error occurred while enabling two-factor authentication.").WithCause(err))
+		return
+	}
+	if result.ModifiedCount == 0 {
+		response.WriteError(w, r, response.Conflict("Two-factor setup changed", "The two-factor setup was restarted. Please scan the new QR code."))
+		return
+	}
+	h.audit.Record(r, audit.Event{Action: "user.2fa.enable", Entity: "users", EntityID: user.ID.Hex()})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":        "Two-factor authentication enabled. Store the recovery codes somewhere safe; they are shown only once.",
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTwoFactor mematikan 2FA. Membutuhkan password dan kode TOTP atau kode pemulihan,
+// dan ditolak jika role pengguna mewajibkan 2FA.
+func (h *Handler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var request TwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Code == "" || request.Password == "" {
+		response.WriteError(w, r, response.InvalidPayload("A password and code are required."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.principalUser(ctx, w, r)
+	if !ok {
+		return
+	}
+	if user.TwoFactor == nil || !user.TwoFactor.Enabled {
+		response.WriteError(w, r, response.Validation("Two-factor not enabled", "Two-factor authentication is not enabled."))
+		return
+	}
+
+	required, err := h.guard.RoleRequiresTwoFactor(ctx, user.Role)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to disable two-factor", "An error occurred while checking the two-factor policy.").WithCause(err))
+		return
+	}
+	if required {
+		response.WriteError(w, r, response.Forbidden("Access denied", "Two-factor authentication is mandatory for your role."))
+		return
+	}
+
+	// Tebakan password dan kode dibatasi dengan penguncian yang sama seperti LoginTwoFactor
+	attemptKey := twoFactorAttemptKey(user.ID.Hex())
+	wait, err := h.loginRetryAfter(ctx, attemptKey)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to disable two-factor", "An error occurred while checking login attempts.").WithCause(err))
+		return
+	}
+	if wait > 0 {
+		writeTooManyAttempts(w, r, wait)
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
+		logAttemptError(r, "failed to record two-factor failure", h.recordLoginFailure(ctx, attemptKey, accountLockoutThreshold))
+		response.WriteError(w, r, response.Unauthorized("Invalid credentials", "The password you entered is incorrect."))
+		return
+	}
+	valid, err := h.verifySecondFactor(ctx, user, request.Code)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to disable two-factor", "An error occurred while verifying the code.").WithCause(err))
+		return
+	}
+	if !valid {
+		logAttemptError(r, "failed to record two-factor failure", h.recordLoginFailure(ctx, attemptKey, accountLockoutThreshold))
+		response.WriteError(w, r, response.Validation("Invalid code", "The code is incorrect or has already been used."))
+		return
+	}
+	logAttemptError(r, "failed to clear two-factor attempts", h.clearLoginAttempts(ctx, attemptKey))
+
+	_, err = h.repos.Users.UpdateOne(ctx,
+		bson.M{"_id": user.ID},
+		bson.M{"$unset": bson.M{"two_factor": ""}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to disable two-factor", "An error occurred while disabling two-factor authentication.").WithCause(err))
+		return
+	}
+	h.audit.Record(r, audit.Event{Action: "user.2fa.disable", Entity: "users", EntityID: user.ID.Hex()})
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes mengganti seluruh kode pemulihan. Membutuhkan kode TOTP yang valid.
+func (h *Handler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var request TwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Code == "" {
+		response.WriteError(w, r, response.InvalidPayload("A code is required."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.principalUser(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	attemptKey := twoFactorAttemptKey(user.ID.Hex())
+	wait, err := h.loginRetryAfter(ctx, attemptKey)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to regenerate recovery codes", "An error occurred while checking login attempts.").WithCause(err))
+		return
+	}
+	if wait > 0 {
+		writeTooManyAttempts(w, r, wait)
+		return
+	}
+
+	valid, err := h.verifyTOTP(ctx, user, request.Code)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to regenerate recovery codes", "An error occurred while verifying the code.").WithCause(err))
+		return
+	}
+	if !valid {
+		logAttemptError(r, "failed to record two-factor failure", h.recordLoginFailure(ctx, attemptKey, accountLockoutThreshold))
+		response.WriteError(w, r, response.Validation("Invalid code", "The code is incorrect or has already been used."))
+		return
+	}
+	logAttemptError(r, "failed to clear two-factor attempts", h.clearLoginAttempts(ctx, attemptKey))
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to regenerate recovery codes", "An error occurred while generating recovery codes.").WithCause(err))
+		return
+	}
+	_, err = h.repos.Users.UpdateOne(ctx,
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"two_factor.recovery_codes": hashes, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to regenerate recovery codes", "An error occurred while saving recovery codes.").WithCause(err))
+		return
+	}
+	h.audit.Record(r, audit.Event{Action: "user.2fa.recovery_codes", Entity: "users", EntityID: user.ID.Hex()})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":        "Recovery codes regenerated. Previous codes no longer work.",
+		"recovery_codes": codes,
+	})
+}
+
+// LoginTwoFactor menyelesaikan login dua langkah: token tantangan dari LoginUsers ditukar
+// dengan access token dan refresh token jika kode TOTP atau kode pemulihan benar
+func (h *Handler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var request TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.ChallengeToken == "" || request.Code == "" {
+		response.WriteError(w, r, response.InvalidPayload("A challenge_token and code are required."))
+		return
+	}
+
+	payload, err := metric.DecodeToken(request.ChallengeToken)
+	if err != nil || payload.Purpose != metric.PurposeTwoFactorChallenge {
+		response.WriteError(w, r, response.Unauthorized("Invalid challenge token", "The two-factor challenge is invalid or has expired. Please log in again."))
+		return
+	}
+	userID, err := primitive.ObjectIDFromHex(payload.UserID)
+	if err != nil {
+		response.WriteError(w, r, response.Unauthorized("Invalid challenge token", "The two-factor challenge is invalid or has expired. Please log in again."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	// Batasi tebakan kode dengan mekanisme penguncian yang sama seperti login
+	attemptKey := twoFactorAttemptKey(userID.Hex())
+	wait, err := h.loginRetryAfter(ctx, attemptKey)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to login", "An error occurred while processing the login request.").WithCause(err))
+		return
+	}
+	if wait > 0 {
+		writeTooManyAttempts(w, r, wait)
+		return
+	}
+
+	user, err := h.repos.Users.FindByID(ctx, userID)
+	if err != nil || user.Status == model.UserStatusSuspended || user.Status == model.UserStatusDeleted {
+		response.WriteError(w, r, response.Unauthorized("Invalid challenge token", "The two-factor challenge is invalid or has expired. Please log in again."))
+		return
+	}
+
+	valid, err := h.verifySecondFactor(ctx, user, request.Code)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to login", "An error occurred while verifying the code.").WithCause(err))
+		return
+	}
+	if !valid {
+		logAttemptError(r, "failed to record two-factor failure", h.recordLoginFailure(ctx, attemptKey, accountLockoutThreshold))
+		response.WriteError(w, r, response.Unauthorized("Invalid code", "The code is incorrect or has already been used."))
+		return
+	}
+	logAttemptError(r, "failed to clear two-factor attempts", h.clearLoginAttempts(ctx, attemptKey))
+
+	h.writeLoginSuccess(ctx, w, r, user)
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"plastiqu_co/helper/totp"
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// enableTwoFactor mendaftarkan TOTP dengan token yang diberikan dan mengembalikan secret,
+// kode yang dipakai untuk konfirmasi, serta kode pemulihan
+func enableTwoFactor(t *testing.T, h *Handler, token string) (secret, code string, recoveryCodes []string) {
+	t.Helper()
+	status, body := callWithToken(t, h.guard.AuthenticateEnrollment(http.HandlerFunc(h.SetupTwoFactor)), http.MethodPost, "/user/2fa/setup", token, "")
+	if status != http.StatusOK {
+		t.Fatalf("setup 2FA: status %d, body %v", status, body)
+	}
+	secret = body["secret"].(string)
+	code, err := totp.Code(secret, time.Now())
+	if err != nil {
+		t.Fatalf("totp code: %v", err)
+	}
+	status, body = callWithToken(t, h.guard.AuthenticateEnrollment(http.HandlerFunc(h.EnableTwoFactor)), http.MethodPost, "/user/2fa/enable", token, `{"code":"`+code+`"}`)
+	if status != http.StatusOK {
+		t.Fatalf("enable 2FA: status %d, body %v", status, body)
+	}
+	for _, c := range body["recovery_codes"].([]interface{}) {
+		recoveryCodes = append(recoveryCodes, c.(string))
+	}
+	return secret, code, recoveryCodes
+}
+
+// loginTwoFactor menyelesaikan login dua langkah dan mengembalikan status serta body respon
+func loginTwoFactor(t *testing.T, h *Handler, challenge, code string) (int, map[string]interface{}) {
+	t.Helper()
+	return call(t, h.LoginTwoFactor, http.MethodPost, "/login/2fa", `{"challenge_token":"`+challenge+`","code":"`+code+`"}`)
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	h, _, mail := newTestHandler(t)
+	registerVerified(t, h, mail)
+	accessToken := login(t, h)["token"].(string)
+	secret, enrollCode, recoveryCodes := enableTwoFactor(t, h, accessToken)
+
+	// Login dengan password saja hanya menghasilkan token tantangan
+	body := login(t, h)
+	if body["two_factor_required"] != true || body["token"] != nil {
+		t.Fatalf("login with 2FA enabled: %v, want a challenge without tokens", body)
+	}
+	challenge := body["challenge_token"].(string)
+
+	// Token tantangan bukan access token, dan access token bukan token tantangan
+	if status, _ := callWithToken(t, h.guard.Authenticate(http.HandlerFunc(h.RegenerateRecoveryCodes)), http.MethodPost, "/user/2fa/recovery-codes", challenge, `{"code":"000000"}`); status != http.StatusUnauthorized {
+		t.Errorf("challenge token as bearer token: status %d, want %d", status, http.StatusUnauthorized)
+	}
+	if status, _ := loginTwoFactor(t, h, accessToken, enrollCode); status != http.StatusUnauthorized {
+		t.Errorf("access token as challenge token: status %d, want %d", status, http.StatusUnauthorized)
+	}
+
+	// Kode TOTP yang sudah dipakai saat pendaftaran tidak dapat dipakai lagi
+	if status, _ := loginTwoFactor(t, h, challenge, enrollCode); status != http.StatusUnauthorized {
+		t.Errorf("replayed enrollment code: status %d, want %d", status, http.StatusUnauthorized)
+	}
+	next, err := totp.Code(secret, time.Now().Add(totp.Period*time.Second))
+	if err != nil {
+		t.Fatalf("totp code: %v", err)
+	}
+	if status, body := loginTwoFactor(t, h, challenge, next); status != http.StatusOK || body["token"] == nil {
+		t.Fatalf("login with the next TOTP code: status %d, body %v", status, body)
+	}
+	if status, _ := loginTwoFactor(t, h, challenge, next); status != http.StatusUnauthorized {
+		t.Errorf("replayed TOTP code: status %d, want %d", status, http.StatusUnauthorized)
+	}
+
+	// Kode pemulihan hanya berlaku sekali
+	if status, body := loginTwoFactor(t, h, challenge, recoveryCodes[0]); status != http.StatusOK {
+		t.Fatalf("login with a recovery code: status %d, body %v", status, body)
+	}
+	if status, _ := loginTwoFactor(t, h, challenge, recoveryCodes[0]); status != http.StatusUnauthorized {
+		t.Errorf("reused recovery code: status %d, want %d", status, http.StatusUnauthorized)
+	}
+}
+
+func TestRoleRequiringTwoFactor(t *testing.T) {
+	h, repos, mail := newTestHandler(t)
+	registerVerified(t, h, mail)
+	before := login(t, h)
+
+	ctx := context.Background()
+	if err := repos.Roles.EnsureDefaults(ctx); err != nil {
+		t.Fatalf("seed roles: %v", err)
+	}
+	if _, err := repos.Roles.UpdateOne(ctx, bson.M{"name": model.RoleUser}, bson.M{"$set": bson.M{"require_two_factor": true}}); err != nil {
+		t.Fatalf("update role: %v", err)
+	}
+	h.guard.InvalidateRoleCache(model.RoleUser)
+
+	// Sesi yang dibuat sebelum kebijakan berlaku tidak dapat diperpanjang
+	if status, _ := refresh(t, h, before["refresh_token"].(string)); status != http.StatusUnauthorized {
+		t.Errorf("refresh without 2FA: status %d, want %d", status, http.StatusUnauthorized)
+	}
+
+	// Login hanya menghasilkan token pendaftaran yang tidak berlaku di endpoint lain
+	body := login(t, h)
+	if body["two_factor_enrollment_required"] != true || body["token"] != nil {
+		t.Fatalf("login without 2FA: %v, want an enrollment token only", body)
+	}
+	enrollment := body["enrollment_token"].(string)
+	if status, _ := callWithToken(t, h.guard.Authenticate(http.HandlerFunc(h.RegenerateRecoveryCodes)), http.MethodPost, "/user/2fa/recovery-codes", enrollment, `{"code":"000000"}`); status != http.StatusUnauthorized {
+		t.Errorf("enrollment token as bearer token: status %d, want %d", status, http.StatusUnauthorized)
+	}
+
+	_, _, recoveryCodes := enableTwoFactor(t, h, enrollment)
+	status, body := loginTwoFactor(t, h, login(t, h)["challenge_token"].(string), recoveryCodes[0])
+	if status != http.StatusOK {
+		t.Fatalf("login after enrollment: status %d, body %v", status, body)
+	}
+
+	// 2FA tidak dapat dimatikan selama role mewajibkannya
+	status, _ = callWithToken(t, h.guard.Authenticate(http.HandlerFunc(h.DisableTwoFactor)), http.MethodPost, "/user/2fa/disable", body["token"].(string),
+		`{"password":"`+testPassword+`","code":"`+recoveryCodes[1]+`"}`)
+	if status != http.StatusForbidden {
+		t.Errorf("disable mandatory 2FA: status %d, want %d", status, http.StatusForbidden)
+	}
+}
+
+func TestTwoFactorCodeGuessingIsLimited(t *testing.T) {
+	h, _, mail := newTestHandler(t)
+	registerVerified(t, h, mail)
+	token := login(t, h)["token"].(string)
+	secret, _, _ := enableTwoFactor(t, h, token)
+
+	wrong := "000000"
+	if _, ok := totp.Validate(secret, wrong, time.Now()); ok {
+		wrong = "000001"
+	}
+	regenerate := h.guard.Authenticate(http.HandlerFunc(h.RegenerateRecoveryCodes))
+	for attempt := 1; ; attempt++ {
+		status, _ := callWithToken(t, regenerate, http.MethodPost, "/user/2fa/recovery-codes", token, `{"code":"`+wrong+`"}`)
+		if status == http.StatusTooManyRequests {
+			break
+		}
+		if status != http.StatusBadRequest || attempt > loginFreeAttempts+1 {
+			t.Fatalf("wrong code attempt %d: status %d, want %d or %d", attempt, status, http.StatusBadRequest, http.StatusTooManyRequests)
+		}
+	}
+
+	// Penguncian berlaku juga untuk mematikan 2FA
+	status, _ := callWithToken(t, h.guard.Authenticate(http.HandlerFunc(h.DisableTwoFactor)), http.MethodPost, "/user/2fa/disable", token,
+		`{"password":"`+testPassword+`","code":"`+wrong+`"}`)
+	if status != http.StatusTooManyRequests {
+		t.Errorf("disable while locked out: status %d, want %d", status, http.StatusTooManyRequests)
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"plastiqu_co/helper/response"
+	"plastiqu_co/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NormalizeEmail merapikan email sebelum disimpan atau dicari
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// UserConflicts mengecek apakah email atau username sudah dipakai akun lain.
+// Hasilnya berupa pesan error per field; map kosong berarti tidak ada konflik.
+func (h *Handler) UserConflicts(ctx context.Context, email, username string, exclude interface{}) (map[string]string, error) {
+	opts := options.Count().SetCollation(repository.CaseInsensitive).SetLimit(1)
+	conflicts := map[string]string{}
+
+	checks := []struct {
+		field, value, message string
+	}{
+		{"email", email, "Email is already registered."},
+		{"username", username, "Username is already taken."},
+	}
+	for _, check := range checks {
+		if check.value == "" {
+			continue
+		}
+		filter := bson.M{check.field: check.value}
+		if exclude != nil {
+			filter["_id"] = bson.M{"$ne": exclude}
+		}
+		count, err := h.repos.Users.Count(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			conflicts[check.field] = check.message
+		}
+	}
+	return conflicts, nil
+}
+
+// DuplicateKeyConflicts menerjemahkan error duplicate key dari index unik users menjadi pesan per field
+func DuplicateKeyConflicts(err error) (map[string]string, bool) {
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, false
+	}
+	switch {
+	case strings.Contains(err.Error(), repository.UserEmailIndex):
+		return map[string]string{"email": "Email is already registered."}, true
+	case strings.Contains(err.Error(), repository.UserUsernameIndex):
+		return map[string]string{"username": "Username is already taken."}, true
+	}
+	return map[string]string{}, true
+}
+
+// ConflictError membuat error 409 beserta daftar field yang bentrok
+func ConflictError(fields map[string]string) *response.Error {
+	return response.Conflict("Conflict", "An account with the same details already exists.").WithFields(fields)
+}
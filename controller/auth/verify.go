@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"plastiqu_co/helper/mailer"
+	"plastiqu_co/helper/metric"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// emailVerificationTTL adalah masa berlaku tautan verifikasi
+	emailVerificationTTL = 24 * time.Hour
+	// verificationResendInterval adalah jeda minimum antar pengiriman email verifikasi
+	verificationResendInterval = time.Minute
+	// verificationMaxPerDay membatasi jumlah permintaan kirim ulang per email dalam 24 jam
+	verificationMaxPerDay = 5
+)
+
+// sendVerificationEmail membuat token verifikasi baru untuk user dan mengirimkannya lewat email
+func (h *Handler) sendVerificationEmail(ctx context.Context, user model.Users) error {
+	token, err := metric.GenerateOpaqueToken(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = h.repos.EmailVerifications.Insert(ctx, model.EmailVerification{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: metric.HashToken(token),
+		ExpiresAt: now.Add(emailVerificationTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	link := h.mail.BaseURL + "/verify-email?token=" + url.QueryEscape(token)
+	return h.mail.Sender.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email akun Plastiqu",
+		Body: fmt.Sprintf("Halo %s,\n\nSilakan verifikasi email Anda dengan membuka tautan berikut:\n%s\n\nTautan ini berlaku selama 24 jam.\n",
+			user.Username, link),
+	})
+}
+
+// VerifyEmail mengaktifkan akun berdasarkan token verifikasi pada query parameter "token"
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		response.WriteError(w, r, response.Validation("Invalid request", "A verification token is required."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	verification, err := h.repos.EmailVerifications.FindByHash(ctx, metric.HashToken(token))
+	if err != nil || verification.UsedAt != nil || time.Now().After(verification.ExpiresAt) {
+		response.WriteError(w, r, response.Validation("Invalid verification token", "The verification link is invalid, has expired or has already been used."))
+		return
+	}
+
+	// Tandai token terpakai; filter used_at memastikan token hanya bisa dipakai sekali
+	now := time.Now()
+	result, err := h.repos.EmailVerifications.UpdateOne(ctx,
+		bson.M{"_id": verification.ID, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": now}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to verify email", "An error occurred while verifying the email.").WithCause(err))
+		return
+	}
+	if result.ModifiedCount == 0 {
+		response.WriteError(w, r, response.Validation("Invalid verification token", "The verification link is invalid, has expired or has already been used."))
+		return
+	}
+
+	// Hanya akun yang masih menunggu verifikasi; akun yang ditangguhkan atau dihapus tidak
+	// boleh aktif kembali lewat tautan lama
+	result, err = h.repos.Users.UpdateOne(ctx,
+		bson.M{"_id": verification.UserID, "email": verification.Email, "status": model.UserStatusPendingVerification},
+		bson.M{"$set": bson.M{
+			"status":            model.UserStatusActive,
+			"email_verified_at": now,
+			"updated_at":        now,
+		}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to verify email", "An error occurred while verifying the email.").WithCause(err))
+		return
+	}
+	if result.MatchedCount == 0 {
+		response.WriteError(w, r, response.Validation("Invalid verification token", "The account is not awaiting email verification."))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": "Email verified successfully",
+	})
+}
+
+// EmailRequest adalah body yang hanya berisi email, dipakai untuk kirim ulang verifikasi dan lupa password
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+// ResendVerificationEmail mengirim ulang email verifikasi dengan pembatasan frekuensi.
+// Respon sukses selalu sama agar tidak membocorkan email mana yang terdaftar.
+func (h *Handler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	var request EmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Email == "" {
+		response.WriteError(w, r, response.InvalidPayload("An email is required."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	accepted := map[string]string{
+		"message": "If the account exists and is pending verification, a new verification email has been sent.",
+	}
+
+	// Batasi frekuensi per email, terdaftar atau tidak, agar respon 429 tidak membocorkan
+	// email mana yang terdaftar
+	email := NormalizeEmail(request.Email)
+	latest, err := h.repos.VerificationRequests.FindOne(ctx, bson.M{"email": email}, options.FindOne().SetSort(bson.M{"created_at": -1}))
+	if err != nil && err != mongo.ErrNoDocuments {
+		response.WriteError(w, r, response.Internal("Failed to send verification email", "An error occurred while sending the verification email.").WithCause(err))
+		return
+	}
+	if err == nil {
+		if wait := verificationResendInterval - time.Since(latest.CreatedAt); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			response.WriteError(w, r, response.TooManyRequests("Too many requests", "Please wait before requesting another verification email."))
+			return
+		}
+	}
+
+	sent, err := h.repos.VerificationRequests.Count(ctx, bson.M{
+		"email":      email,
+		"created_at": bson.M{"$gte": time.Now().Add(-24 * time.Hour)},
+	})
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to send verification email", "An error occurred while sending the verification email.").WithCause(err))
+		return
+	}
+	if sent >= verificationMaxPerDay {
+		response.WriteError(w, r, response.TooManyRequests("Too many requests", "The daily limit for verification emails has been reached."))
+		return
+	}
+
+	err = h.repos.VerificationRequests.Insert(ctx, model.VerificationRequest{ID: primitive.NewObjectID(), Email: email, CreatedAt: time.Now()})
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to send verification email", "An error occurred while sending the verification email.").WithCause(err))
+		return
+	}
+
+	user, err := h.repos.Users.FindByEmail(ctx, email)
+	if err != nil || user.Status != model.UserStatusPendingVerification {
+		response.JSON(w, http.StatusAccepted, accepted)
+		return
+	}
+
+	if err := h.sendVerificationEmail(ctx, user); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to send verification email", "An error occurred while sending the verification email.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, accepted)
+}
@@ -9,192 +9,142 @@ import (
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"plastiqu_co/config"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
 	"plastiqu_co/model"
 )
 
 // CreateBanner untuk menambahkan banner baru
-func CreateBanner(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateBanner(w http.ResponseWriter, r *http.Request) {
 	var banner model.Banner
 
 	// Decode JSON request body ke struct Banner
 	if err := json.NewDecoder(r.Body).Decode(&banner); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded. Please check the structure of your request.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
+		return
+	}
+
+	if err := validate.Struct(banner); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
 	banner.ID = primitive.NewObjectID()
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("banners")
-	_, err := collection.InsertOne(ctx, banner)
+	collection := h.repos.Banners
+	err := collection.Insert(ctx, banner)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to create banner",
-			"message": "An error occurred while creating the banner.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to create banner", "An error occurred while creating the banner.").WithCause(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	response.JSON(w, http.StatusCreated, map[string]interface{}{
 		"message": "Banner created successfully",
 		"banner":  banner,
 	})
 }
 
 // GetBanners untuk mendapatkan semua banner
-func GetBanners(w http.ResponseWriter, r *http.Request) {
-	var banners []model.Banner
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (h *Handler) GetBanners(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("banners")
-	cursor, err := collection.Find(ctx, bson.M{})
+	collection := h.repos.Banners
+	banners, err := collection.Find(ctx, bson.M{})
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to retrieve banners",
-			"message": "An error occurred while retrieving banners.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to retrieve banners", "An error occurred while retrieving banners.").WithCause(err))
 		return
 	}
-	defer cursor.Close(ctx)
-
-	for cursor.Next(ctx) {
-		var banner model.Banner
-		if err := cursor.Decode(&banner); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{
-				"error":   "Failed to decode banner",
-				"message": "An error occurred while decoding banner data.",
-			})
-			return
-		}
-		banners = append(banners, banner)
-	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(banners)
+	response.JSON(w, http.StatusOK, banners)
 }
 
 // GetBannerByID untuk mengambil banner berdasarkan ID
-func GetBannerByID(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetBannerByID(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"])
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid banner ID",
-			"message": "The provided banner ID is not valid.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid banner ID", "The provided banner ID is not valid."))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
 	var banner model.Banner
-	collection := config.Mongoconn.Collection("banners")
-	err = collection.FindOne(ctx, bson.M{"_id": id}).Decode(&banner)
+	collection := h.repos.Banners
+	banner, err = collection.FindOne(ctx, bson.M{"_id": id})
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Banner not found",
-			"message": "The banner you are looking for does not exist.",
-		})
+		response.WriteError(w, r, response.NotFound("Banner not found", "The banner you are looking for does not exist."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(banner)
+	response.JSON(w, http.StatusOK, banner)
 }
 
 // UpdateBanner untuk memperbarui banner berdasarkan ID
-func UpdateBanner(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateBanner(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"])
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid banner ID",
-			"message": "The provided banner ID is not valid.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid banner ID", "The provided banner ID is not valid."))
 		return
 	}
 
 	var banner model.Banner
 	if err := json.NewDecoder(r.Body).Decode(&banner); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded. Please check the structure of your request.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
+		return
+	}
+
+	if err := validate.Patch(banner); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("banners")
+	collection := h.repos.Banners
 	filter := bson.M{"_id": id}
 	update := bson.M{"$set": banner}
 
 	result, err := collection.UpdateOne(ctx, filter, update)
 	if err != nil || result.MatchedCount == 0 {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Banner not found",
-			"message": "The banner you are trying to update does not exist.",
-		})
+		response.WriteError(w, r, response.NotFound("Banner not found", "The banner you are trying to update does not exist."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Banner updated successfully",
 	})
 }
 
 // DeleteBanner untuk menghapus banner berdasarkan ID
-func DeleteBanner(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeleteBanner(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"])
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid banner ID",
-			"message": "The provided banner ID is not valid.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid banner ID", "The provided banner ID is not valid."))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("banners")
+	collection := h.repos.Banners
 	result, err := collection.DeleteOne(ctx, bson.M{"_id": id})
 	if err != nil || result.DeletedCount == 0 {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Banner not found",
-			"message": "The banner you are trying to delete does not exist.",
-		})
+		response.WriteError(w, r, response.NotFound("Banner not found", "The banner you are trying to delete does not exist."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Banner deleted successfully",
 	})
 }
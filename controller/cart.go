@@ -1,33 +1,31 @@
 package controller
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 	"time"
 
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
 	"plastiqu_co/model"
 
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var cartCollection *mongo.Collection
-
-// Initialize the cart collection
-func InitCartController(client *mongo.Client) {
-	cartCollection = client.Database("your_database").Collection("carts") // Ganti "your_database" dengan nama database Anda
-}
-
 // AddToCart untuk menambahkan produk ke keranjang
-func AddToCart(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) AddToCart(w http.ResponseWriter, r *http.Request) {
 	var cart model.Cart
 
 	// Decode JSON request body
 	if err := json.NewDecoder(r.Body).Decode(&cart); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
+		return
+	}
+
+	if err := validate.Struct(cart); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
@@ -35,52 +33,38 @@ func AddToCart(w http.ResponseWriter, r *http.Request) {
 	cart.UpdatedAt = time.Now()
 
 	// Insert cart item into the collection
-	if _, err := cartCollection.InsertOne(context.TODO(), cart); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.repos.Carts.Insert(r.Context(), cart); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to add to cart", "An error occurred while adding the item to the cart.").WithCause(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(cart)
+	response.JSON(w, http.StatusCreated, cart)
 }
 
 // GetCartItems untuk mendapatkan semua item di keranjang untuk pengguna tertentu
-func GetCartItems(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetCartItems(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	userID, err := primitive.ObjectIDFromHex(params["user_id"]) // Mengubah user_id ke ObjectID
 	if err != nil {
-		http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+		response.WriteError(w, r, response.BadRequest("Invalid user ID", "User ID format is incorrect."))
 		return
 	}
 
-	var carts []model.Cart
-
-	cursor, err := cartCollection.Find(context.TODO(), bson.M{"user_id": userID})
+	carts, err := h.repos.Carts.FindByUser(r.Context(), userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		response.WriteError(w, r, response.Internal("Failed to retrieve cart", "An error occurred while retrieving the cart items.").WithCause(err))
 		return
 	}
-	defer cursor.Close(context.TODO())
-
-	for cursor.Next(context.TODO()) {
-		var cart model.Cart
-		if err := cursor.Decode(&cart); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		carts = append(carts, cart)
-	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(carts)
+	response.JSON(w, http.StatusOK, carts)
 }
 
 // UpdateCartItem untuk memperbarui item di keranjang berdasarkan ID
-func UpdateCartItem(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateCartItem(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"]) // Mengubah ID ke ObjectID
 	if err != nil {
-		http.Error(w, "Invalid ID format", http.StatusBadRequest)
+		response.WriteError(w, r, response.BadRequest("Invalid cart item ID", "Cart item ID format is incorrect."))
 		return
 	}
 
@@ -88,7 +72,12 @@ func UpdateCartItem(w http.ResponseWriter, r *http.Request) {
 
 	// Decode JSON request body
 	if err := json.NewDecoder(r.Body).Decode(&updatedCart); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
+		return
+	}
+
+	if err := validate.Patch(updatedCart); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
@@ -98,29 +87,28 @@ func UpdateCartItem(w http.ResponseWriter, r *http.Request) {
 
 	update := bson.M{"$set": updatedCart}
 
-	result, err := cartCollection.UpdateOne(context.TODO(), filter, update)
+	result, err := h.repos.Carts.UpdateOne(r.Context(), filter, update)
 	if err != nil || result.MatchedCount == 0 {
-		http.Error(w, "Cart item not found", http.StatusNotFound)
+		response.WriteError(w, r, response.NotFound("Cart item not found", "No cart item found with the specified ID."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(updatedCart)
+	response.JSON(w, http.StatusOK, updatedCart)
 }
 
 // RemoveFromCart untuk menghapus item dari keranjang berdasarkan ID
-func RemoveFromCart(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"]) // Mengubah ID ke ObjectID
 	if err != nil {
-		http.Error(w, "Invalid ID format", http.StatusBadRequest)
+		response.WriteError(w, r, response.BadRequest("Invalid cart item ID", "Cart item ID format is incorrect."))
 		return
 	}
 
 	// Delete the cart item from the collection
-	result, err := cartCollection.DeleteOne(context.TODO(), bson.M{"_id": id})
+	result, err := h.repos.Carts.DeleteOne(r.Context(), bson.M{"_id": id})
 	if err != nil || result.DeletedCount == 0 {
-		http.Error(w, "Cart item not found", http.StatusNotFound)
+		response.WriteError(w, r, response.NotFound("Cart item not found", "No cart item found with the specified ID."))
 		return
 	}
 
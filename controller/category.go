@@ -9,128 +9,91 @@ import (
 	"github.com/gorilla/mux" // Import gorilla/mux untuk menangani path parameters
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"plastiqu_co/config" 
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
 	"plastiqu_co/model"  
 )
 
 // CreateCategory handles the creation of a new category
-func CreateCategory(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
 	var category model.Category
 
 	// Decode the JSON request body into the category struct
 	err := json.NewDecoder(r.Body).Decode(&category)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded. Please check the structure of your request.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
+		return
+	}
+
+	if err := validate.Struct(category); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
 	// Create the category with current time
 	category.ID = primitive.NewObjectID() // Generate a new ID
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("categories")
-	_, err = collection.InsertOne(ctx, category)
+	collection := h.repos.Categories
+	err = collection.Insert(ctx, category)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to create category",
-			"message": "An error occurred while creating the category.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to create category", "An error occurred while creating the category.").WithCause(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	response.JSON(w, http.StatusCreated, map[string]interface{}{
 		"message": "Category created successfully",
 		"category": category,
 	})
 }
 
 // GetCategories retrieves all categories
-func GetCategories(w http.ResponseWriter, r *http.Request) {
-	var categories []model.Category
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (h *Handler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("categories")
-	cursor, err := collection.Find(ctx, bson.M{})
+	collection := h.repos.Categories
+	categories, err := collection.Find(ctx, bson.M{})
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to retrieve categories",
-			"message": "An error occurred while retrieving categories.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to retrieve categories", "An error occurred while retrieving categories.").WithCause(err))
 		return
 	}
-	defer cursor.Close(ctx)
-
-	for cursor.Next(ctx) {
-		var category model.Category
-		if err := cursor.Decode(&category); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{
-				"error":   "Failed to decode category",
-				"message": "An error occurred while decoding category data.",
-			})
-			return
-		}
-		categories = append(categories, category)
-	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(categories)
+	response.JSON(w, http.StatusOK, categories)
 }
 
 // GetCategoryByID retrieves a category by its ID
-func GetCategoryByID(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetCategoryByID(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"]) // Mengambil ID dari URL path
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid category ID",
-			"message": "The provided category ID is not valid.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid category ID", "The provided category ID is not valid."))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
 	var category model.Category
-	collection := config.Mongoconn.Collection("categories")
-	err = collection.FindOne(ctx, bson.M{"_id": id}).Decode(&category)
+	collection := h.repos.Categories
+	category, err = collection.FindOne(ctx, bson.M{"_id": id})
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Category not found",
-			"message": "The category you are looking for does not exist.",
-		})
+		response.WriteError(w, r, response.NotFound("Category not found", "The category you are looking for does not exist."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(category)
+	response.JSON(w, http.StatusOK, category)
 }
 
 // UpdateCategory updates an existing category
-func UpdateCategory(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"]) // Mengambil ID dari URL path
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid category ID",
-			"message": "The provided category ID is not valid.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid category ID", "The provided category ID is not valid."))
 		return
 	}
 
@@ -138,67 +101,54 @@ func UpdateCategory(w http.ResponseWriter, r *http.Request) {
 	// Decode the JSON request body into the category struct
 	err = json.NewDecoder(r.Body).Decode(&category)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded. Please check the structure of your request.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
+		return
+	}
+
+	if err := validate.Patch(category); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("categories")
+	collection := h.repos.Categories
 	filter := bson.M{"_id": id} // Filter berdasarkan ID
 	update := bson.M{"$set": category}
 
 	result, err := collection.UpdateOne(ctx, filter, update)
 	if err != nil || result.MatchedCount == 0 {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Category not found",
-			"message": "The category you are trying to update does not exist.",
-		})
+		response.WriteError(w, r, response.NotFound("Category not found", "The category you are trying to update does not exist."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Category updated successfully",
 	})
 }
 
 // DeleteCategory deletes an existing category
-func DeleteCategory(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"]) // Mengambil ID dari URL path
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid category ID",
-			"message": "The provided category ID is not valid.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid category ID", "The provided category ID is not valid."))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	collection := config.Mongoconn.Collection("categories")
+	collection := h.repos.Categories
 	result, err := collection.DeleteOne(ctx, bson.M{"_id": id})
 	if err != nil || result.DeletedCount == 0 {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Category not found",
-			"message": "The category you are trying to delete does not exist.",
-		})
+		response.WriteError(w, r, response.NotFound("Category not found", "The category you are trying to delete does not exist."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Category deleted successfully",
 	})
 }
@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"time"
+
+	"plastiqu_co/controller/auth"
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/middleware"
+	"plastiqu_co/repository"
+)
+
+// Handler melayani endpoint katalog, pesanan, pengguna dan administrasi
+type Handler struct {
+	repos *repository.Repositories
+	guard *middleware.Guard
+	audit *audit.Recorder
+	auth  *auth.Handler
+	loc   *time.Location // Zona waktu aplikasi untuk filter tanggal
+}
+
+// NewHandler membuat Handler di atas repository aplikasi. authHandler dipakai untuk
+// mencabut sesi pengguna setelah aksi administratif.
+func NewHandler(repos *repository.Repositories, guard *middleware.Guard, recorder *audit.Recorder, authHandler *auth.Handler, loc *time.Location) *Handler {
+	return &Handler{repos: repos, guard: guard, audit: recorder, auth: authHandler, loc: loc}
+}
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"plastiqu_co/helper/response"
+)
+
+// Healthz menandakan proses masih hidup, tanpa memeriksa dependensi
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+// Readyz menandakan aplikasi siap menerima trafik, yaitu database dapat dihubungi
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	if err := h.repos.Ping(ctx); err != nil {
+		response.WriteError(w, r, response.Unavailable("Not ready", "The database is not reachable."))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"status": "ready",
+	})
+}
@@ -1,34 +1,55 @@
 package controller
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
+	"plastiqu_co/middleware"
 	"plastiqu_co/model"
 
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var orderCollection *mongo.Collection
-
-// Initialize the order collection
-func InitOrderController(client *mongo.Client) {
-	orderCollection = client.Database("your_database").Collection("orders") // Ganti "your_database" dengan nama database Anda
-}
-
 // AddOrder untuk menambahkan pesanan baru
-func AddOrder(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) AddOrder(w http.ResponseWriter, r *http.Request) {
 	var order model.Orders
 
 	// Decode JSON request body
 	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
+		return
+	}
+
+	// Pesanan milik pemanggil; hanya pemilik orders:write yang boleh membuat pesanan untuk pengguna lain
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.WriteError(w, r, response.Unauthorized("Unauthorized", "A valid bearer token is required."))
+		return
+	}
+	if order.UserID.IsZero() {
+		order.UserID = principal.UserID
+	}
+	if order.UserID != principal.UserID {
+		allowed, err := h.guard.HasPermission(r.Context(), model.PermissionOrdersWrite)
+		if err != nil {
+			response.WriteError(w, r, response.Internal("Failed to check permission", "An error occurred while checking your permissions.").WithCause(err))
+			return
+		}
+		if !allowed {
+			response.WriteError(w, r, response.Forbidden("Access denied", "You can only place orders for your own account."))
+			return
+		}
+	}
+
+	if err := validate.Struct(order); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
@@ -46,65 +67,50 @@ func AddOrder(w http.ResponseWriter, r *http.Request) {
 	order.UpdatedAt = time.Now()
 
 	// Insert order into the collection
-	if _, err := orderCollection.InsertOne(context.TODO(), order); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.repos.Orders.Insert(r.Context(), order); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to create order", "An error occurred while creating the order.").WithCause(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(order)
+	response.JSON(w, http.StatusCreated, order)
 }
 
 // GetAllOrders untuk mendapatkan semua pesanan
-func GetAllOrders(w http.ResponseWriter, r *http.Request) {
-	var orders []model.Orders
-
-	cursor, err := orderCollection.Find(context.TODO(), bson.D{{}})
+func (h *Handler) GetAllOrders(w http.ResponseWriter, r *http.Request) {
+	orders, err := h.repos.Orders.Find(r.Context(), bson.M{})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		response.WriteError(w, r, response.Internal("Failed to retrieve orders", "An error occurred while retrieving orders.").WithCause(err))
 		return
 	}
-	defer cursor.Close(context.TODO())
-
-	for cursor.Next(context.TODO()) {
-		var order model.Orders
-		if err := cursor.Decode(&order); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		orders = append(orders, order)
-	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(orders)
+	response.JSON(w, http.StatusOK, orders)
 }
 
 // GetOrderByID untuk mendapatkan pesanan berdasarkan ID
-func GetOrderByID(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"]) // Mengubah ID ke ObjectID
 	if err != nil {
-		http.Error(w, "Invalid ID format", http.StatusBadRequest)
+		response.WriteError(w, r, response.BadRequest("Invalid order ID", "Order ID format is incorrect."))
 		return
 	}
 
 	var order model.Orders
-	err = orderCollection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&order)
+	order, err = h.repos.Orders.FindOne(r.Context(), bson.M{"_id": id})
 	if err != nil {
-		http.Error(w, "Order not found", http.StatusNotFound)
+		response.WriteError(w, r, response.NotFound("Order not found", "No order found with the specified ID."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(order)
+	response.JSON(w, http.StatusOK, order)
 }
 
 // UpdateOrder untuk memperbarui pesanan berdasarkan ID
-func UpdateOrder(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"]) // Mengubah ID ke ObjectID
 	if err != nil {
-		http.Error(w, "Invalid ID format", http.StatusBadRequest)
+		response.WriteError(w, r, response.BadRequest("Invalid order ID", "Order ID format is incorrect."))
 		return
 	}
 
@@ -112,7 +118,12 @@ func UpdateOrder(w http.ResponseWriter, r *http.Request) {
 
 	// Decode JSON request body
 	if err := json.NewDecoder(r.Body).Decode(&updatedOrder); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
+		return
+	}
+
+	if err := validate.Patch(updatedOrder); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
@@ -120,29 +131,28 @@ func UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	filter := bson.M{"_id": id}
 	update := bson.M{"$set": updatedOrder}
 
-	result, err := orderCollection.UpdateOne(context.TODO(), filter, update)
+	result, err := h.repos.Orders.UpdateOne(r.Context(), filter, update)
 	if err != nil || result.MatchedCount == 0 {
-		http.Error(w, "Order not found", http.StatusNotFound)
+		response.WriteError(w, r, response.NotFound("Order not found", "No order found with the specified ID."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(updatedOrder)
+	response.JSON(w, http.StatusOK, updatedOrder)
 }
 
 // DeleteOrder untuk menghapus pesanan berdasarkan ID
-func DeleteOrder(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"]) // Mengubah ID ke ObjectID
 	if err != nil {
-		http.Error(w, "Invalid ID format", http.StatusBadRequest)
+		response.WriteError(w, r, response.BadRequest("Invalid order ID", "Order ID format is incorrect."))
 		return
 	}
 
 	// Delete the order from the collection
-	result, err := orderCollection.DeleteOne(context.TODO(), bson.M{"_id": id})
+	result, err := h.repos.Orders.DeleteOne(r.Context(), bson.M{"_id": id})
 	if err != nil || result.DeletedCount == 0 {
-		http.Error(w, "Order not found", http.StatusNotFound)
+		response.WriteError(w, r, response.NotFound("Order not found", "No order found with the specified ID."))
 		return
 	}
 
@@ -156,17 +166,17 @@ func generateOrderCode(userID primitive.ObjectID) string {
 }
 
 // AdvanceOrderStatus untuk mengubah status pesanan ke status berikutnya
-func AdvanceOrderStatus(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) AdvanceOrderStatus(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := primitive.ObjectIDFromHex(params["id"]) // Mengubah ID ke ObjectID
 	if err != nil {
-		http.Error(w, "Invalid ID format", http.StatusBadRequest)
+		response.WriteError(w, r, response.BadRequest("Invalid order ID", "Order ID format is incorrect."))
 		return
 	}
 
-	var order model.Orders
-	if err := orderCollection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&order); err != nil {
-		http.Error(w, "Order not found", http.StatusNotFound)
+	order, err := h.repos.Orders.FindOne(r.Context(), bson.M{"_id": id})
+	if err != nil {
+		response.WriteError(w, r, response.NotFound("Order not found", "No order found with the specified ID."))
 		return
 	}
 
@@ -184,7 +194,7 @@ func AdvanceOrderStatus(w http.ResponseWriter, r *http.Request) {
 	case "Dikirim":
 		newStatus = "Selesai"
 	default:
-		http.Error(w, "No further status transition available", http.StatusBadRequest)
+		response.WriteError(w, r, response.Validation("Invalid status transition", "No further status transition is available for this order."))
 		return
 	}
 
@@ -192,12 +202,18 @@ func AdvanceOrderStatus(w http.ResponseWriter, r *http.Request) {
 	filter := bson.M{"_id": id}
 	update := bson.M{"$set": bson.M{"status": newStatus}}
 
-	result, err := orderCollection.UpdateOne(context.TODO(), filter, update)
+	result, err := h.repos.Orders.UpdateOne(r.Context(), filter, update)
 	if err != nil || result.MatchedCount == 0 {
-		http.Error(w, "Order not found", http.StatusNotFound)
+		response.WriteError(w, r, response.NotFound("Order not found", "No order found with the specified ID."))
 		return
 	}
+	h.audit.Record(r, audit.Event{
+		Action:   "order.advance",
+		Entity:   "orders",
+		EntityID: id.Hex(),
+		Before:   bson.M{"status": order.Status},
+		After:    bson.M{"status": newStatus},
+	})
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(newStatus)
+	response.JSON(w, http.StatusOK, newStatus)
 }
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAddOrderBelongsToTheCaller(t *testing.T) {
+	h, _ := newTestHandler(t)
+	other := primitive.NewObjectID().Hex()
+	order := `"product_id":"` + primitive.NewObjectID().Hex() + `","fullname":"Ana","phone":"081234567890","address":"Jl. Merdeka 1",` +
+		`"product_name":"Botol","amount":1,"price":15000,"payment_method":"COD"`
+
+	tests := []struct {
+		name   string
+		role   string
+		body   string
+		status int
+	}{
+		{"own order without user_id", model.RoleUser, `{` + order + `}`, http.StatusCreated},
+		{"order for another user", model.RoleUser, `{"user_id":"` + other + `",` + order + `}`, http.StatusForbidden},
+		{"order for another user without orders:write", model.RoleWarehouse, `{"user_id":"` + other + `",` + order + `}`, http.StatusForbidden},
+		{"order for another user with orders:write", model.RoleAdmin, `{"user_id":"` + other + `",` + order + `}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := callAs(t, tt.role, h.AddOrder, http.MethodPost, "/orders", nil, tt.body)
+			if status != tt.status {
+				t.Fatalf("status %d, want %d (body %v)", status, tt.status, body)
+			}
+			if status == http.StatusCreated && body["user_id"] == nil {
+				t.Errorf("order without an owner: %v", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pagination membaca query parameter "page" (mulai dari 1) dan "limit"
+func pagination(r *http.Request) (page, limit int64) {
+	page, _ = strconv.ParseInt(r.URL.Query().Get("page"), 10, 64)
+	if page < 1 {
+		page = 1
+	}
+	limit, _ = strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+	if limit < 1 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return
+}
+
+// parseDateParam membaca tanggal dalam format RFC3339 atau YYYY-MM-DD. Tanggal tanpa
+// zona waktu dibaca pada zona waktu loc. Nilai kosong menghasilkan time.Time kosong tanpa error.
+func parseDateParam(value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, loc)
+}
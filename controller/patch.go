@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// patchFields membangun $set dari field teratas struct yang tidak bernilai nol, dengan nama
+// field dari tag bson. Sama seperti validate.Patch, field yang kosong dianggap tidak dikirim
+// sehingga nilai yang tersimpan (mis. rating dan sold produk) tidak tertimpa. _id tidak ikut.
+func patchFields(v interface{}) bson.M {
+	fields := bson.M{}
+	value := reflect.Indirect(reflect.ValueOf(v))
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("bson"), ",")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if !field.IsExported() || name == "-" || name == "_id" || value.Field(i).IsZero() {
+			continue
+		}
+		fields[name] = value.Field(i).Interface()
+	}
+	return fields
+}
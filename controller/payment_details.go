@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
-	"plastiqu_co/config"
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
 	"plastiqu_co/model"
 	"time"
 
@@ -13,223 +15,164 @@ import (
 )
 
 // CreatePaymentDetails creates a new payment detail
-func CreatePaymentDetails(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreatePaymentDetails(w http.ResponseWriter, r *http.Request) {
 	var payment model.PaymentDetails
 
 	err := json.NewDecoder(r.Body).Decode(&payment)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
+		return
+	}
+
+	if err := validate.Struct(payment); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
 	payment.ID = primitive.NewObjectID() // Generate a new ID
-	collection := config.Mongoconn.Collection("payment_details")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	collection := h.repos.PaymentDetails
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	_, err = collection.InsertOne(ctx, payment)
+	err = collection.Insert(ctx, payment)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to create payment details",
-			"message": "An error occurred while creating payment details.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to create payment details", "An error occurred while creating payment details.").WithCause(err))
 		return
 	}
+	h.audit.Record(r, audit.Event{Action: "payment_details.create", Entity: "payment_details", EntityID: payment.ID.Hex(), After: payment})
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(payment)
+	response.JSON(w, http.StatusCreated, payment)
 }
 
 // GetPaymentDetails retrieves all payment details
-func GetPaymentDetails(w http.ResponseWriter, r *http.Request) {
-	collection := config.Mongoconn.Collection("payment_details")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (h *Handler) GetPaymentDetails(w http.ResponseWriter, r *http.Request) {
+	collection := h.repos.PaymentDetails
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := collection.Find(ctx, bson.M{})
+	payments, err := collection.Find(ctx, bson.M{})
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to retrieve payment details",
-			"message": "An error occurred while retrieving payment details.",
-		})
-		return
-	}
-	defer cursor.Close(ctx)
-
-	var payments []model.PaymentDetails
-	for cursor.Next(ctx) {
-		var payment model.PaymentDetails
-		if err := cursor.Decode(&payment); err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{
-				"error":   "Failed to decode payment detail",
-				"message": "An error occurred while decoding payment details.",
-			})
-			return
-		}
-		payments = append(payments, payment)
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(payments)
+		response.WriteError(w, r, response.Internal("Failed to retrieve payment details", "An error occurred while retrieving payment details.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, payments)
 }
 
 // GetPaymentDetailByID retrieves a payment detail by ID
-func GetPaymentDetailByID(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetPaymentDetailByID(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id") // Assume the ID is passed as a query parameter
 
 	if id == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Missing payment detail ID",
-			"message": "You must provide an ID to retrieve payment details.",
-		})
+		response.WriteError(w, r, response.BadRequest("Missing payment detail ID", "You must provide an ID to retrieve payment details."))
 		return
 	}
 
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid payment detail ID",
-			"message": "Payment detail ID format is incorrect.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid payment detail ID", "Payment detail ID format is incorrect."))
 		return
 	}
 
-	collection := config.Mongoconn.Collection("payment_details")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	collection := h.repos.PaymentDetails
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
 	var payment model.PaymentDetails
-	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&payment)
+	payment, err = collection.FindOne(ctx, bson.M{"_id": objID})
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Payment detail not found",
-			"message": "No payment detail found with the specified ID.",
-		})
+		response.WriteError(w, r, response.NotFound("Payment detail not found", "No payment detail found with the specified ID."))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(payment)
+	response.JSON(w, http.StatusOK, payment)
 }
 
 // UpdatePaymentDetails updates an existing payment detail
-func UpdatePaymentDetails(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdatePaymentDetails(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id") // Assume the ID is passed as a query parameter
 
 	if id == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Missing payment detail ID",
-			"message": "You must provide an ID to update payment details.",
-		})
+		response.WriteError(w, r, response.BadRequest("Missing payment detail ID", "You must provide an ID to update payment details."))
 		return
 	}
 
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid payment detail ID",
-			"message": "Payment detail ID format is incorrect.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid payment detail ID", "Payment detail ID format is incorrect."))
 		return
 	}
 
 	var payment model.PaymentDetails
 	err = json.NewDecoder(r.Body).Decode(&payment)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
 		return
 	}
 
-	collection := config.Mongoconn.Collection("payment_details")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := validate.Patch(payment); err != nil {
+		response.WriteError(w, r, err)
+		return
+	}
+
+	// Hanya field yang dikirim yang diperbarui
+	fields := patchFields(payment)
+	if len(fields) == 0 {
+		response.WriteError(w, r, response.Validation("Nothing to update", "Provide at least one field to update."))
+		return
+	}
+
+	collection := h.repos.PaymentDetails
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": payment})
+	before := collection.Snapshot(ctx, bson.M{"_id": objID})
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": fields})
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to update payment details",
-			"message": "An error occurred while updating payment details.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to update payment details", "An error occurred while updating payment details.").WithCause(err))
 		return
 	}
+	h.audit.Record(r, audit.Event{
+		Action:   "payment_details.update",
+		Entity:   "payment_details",
+		EntityID: objID.Hex(),
+		Before:   before,
+		After:    collection.Snapshot(ctx, bson.M{"_id": objID}),
+	})
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Payment details updated successfully",
 	})
 }
 
 // DeletePaymentDetails deletes a payment detail by ID
-func DeletePaymentDetails(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeletePaymentDetails(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id") // Assume the ID is passed as a query parameter
 
 	if id == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Missing payment detail ID",
-			"message": "You must provide an ID to delete payment details.",
-		})
+		response.WriteError(w, r, response.BadRequest("Missing payment detail ID", "You must provide an ID to delete payment details."))
 		return
 	}
 
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid payment detail ID",
-			"message": "Payment detail ID format is incorrect.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid payment detail ID", "Payment detail ID format is incorrect."))
 		return
 	}
 
-	collection := config.Mongoconn.Collection("payment_details")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	collection := h.repos.PaymentDetails
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
+	before := collection.Snapshot(ctx, bson.M{"_id": objID})
 	_, err = collection.DeleteOne(ctx, bson.M{"_id": objID})
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to delete payment details",
-			"message": "An error occurred while deleting payment details.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to delete payment details", "An error occurred while deleting payment details.").WithCause(err))
 		return
 	}
+	h.audit.Record(r, audit.Event{Action: "payment_details.delete", Entity: "payment_details", EntityID: objID.Hex(), Before: before})
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Payment details deleted successfully",
 	})
 }
@@ -1,32 +1,31 @@
 package controller
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
 	"plastiqu_co/model"
 
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var productCollection *mongo.Collection
-
-// Initialize the product collection
-func InitProductController(client *mongo.Client) {
-	productCollection = client.Database("your_database").Collection("products") // Ganti "your_database" dengan nama database Anda
-}
-
 // AddProduct untuk menambahkan produk baru
-func AddProduct(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) AddProduct(w http.ResponseWriter, r *http.Request) {
 	var product model.Product
 
 	// Decode JSON request body
 	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
+		return
+	}
+
+	if err := validate.Struct(product); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
@@ -40,41 +39,27 @@ func AddProduct(w http.ResponseWriter, r *http.Request) {
 	product.Sold = 0     // Default sold count
 
 	// Insert product into the collection
-	if _, err := productCollection.InsertOne(context.TODO(), product); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.repos.Products.Insert(r.Context(), product); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to create product", "An error occurred while creating the product.").WithCause(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(product)
+	response.JSON(w, http.StatusCreated, product)
 }
 
 // GetAllProducts untuk mendapatkan semua produk
-func GetAllProducts(w http.ResponseWriter, r *http.Request) {
-	var products []model.Product
-
-	cursor, err := productCollection.Find(context.TODO(), bson.D{{}})
+func (h *Handler) GetAllProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := h.repos.Products.Find(r.Context(), bson.M{})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		response.WriteError(w, r, response.Internal("Failed to retrieve products", "An error occurred while retrieving products.").WithCause(err))
 		return
 	}
-	defer cursor.Close(context.TODO())
-
-	for cursor.Next(context.TODO()) {
-		var product model.Product
-		if err := cursor.Decode(&product); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		products = append(products, product)
-	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(products)
+	response.JSON(w, http.StatusOK, products)
 }
 
 // GetProductByID untuk mendapatkan produk berdasarkan ID
-func GetProductByID(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetProductByID(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id := params["id"]
 
@@ -82,22 +67,21 @@ func GetProductByID(w http.ResponseWriter, r *http.Request) {
 	// Convert id string to ObjectID
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		response.WriteError(w, r, response.BadRequest("Invalid product ID", "Product ID format is incorrect."))
 		return
 	}
 
-	err = productCollection.FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&product)
+	product, err = h.repos.Products.FindOne(r.Context(), bson.M{"_id": objID})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		response.WriteError(w, r, response.NotFound("Product not found", "No product found with the specified ID."))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(product)
+	response.JSON(w, http.StatusOK, product)
 }
 
 // UpdateProduct untuk memperbarui produk berdasarkan ID
-func UpdateProduct(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id := params["id"]
 
@@ -105,49 +89,73 @@ func UpdateProduct(w http.ResponseWriter, r *http.Request) {
 
 	// Decode JSON request body
 	if err := json.NewDecoder(r.Body).Decode(&updatedProduct); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
+		return
+	}
+
+	if err := validate.Patch(updatedProduct); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
 	// Convert id string to ObjectID
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		response.WriteError(w, r, response.BadRequest("Invalid product ID", "Product ID format is incorrect."))
 		return
 	}
 
-	// Prepare the update
+	// Hanya field yang dikirim yang diperbarui
+	fields := patchFields(updatedProduct)
+	if len(fields) == 0 {
+		response.WriteError(w, r, response.Validation("Nothing to update", "Provide at least one field to update."))
+		return
+	}
 	filter := bson.M{"_id": objID}
-	update := bson.M{"$set": updatedProduct}
+	update := bson.M{"$set": fields}
 
-	result, err := productCollection.UpdateOne(context.TODO(), filter, update)
+	before := h.repos.Products.Snapshot(r.Context(), filter)
+	result, err := h.repos.Products.UpdateOne(r.Context(), filter, update)
 	if err != nil || result.MatchedCount == 0 {
-		http.Error(w, "Product not found", http.StatusNotFound)
+		response.WriteError(w, r, response.NotFound("Product not found", "No product found with the specified ID."))
 		return
 	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(updatedProduct)
+	h.audit.Record(r, audit.Event{
+		Action:   "product.update",
+		Entity:   "products",
+		EntityID: objID.Hex(),
+		Before:   before,
+		After:    h.repos.Products.Snapshot(r.Context(), filter),
+	})
+
+	product, err := h.repos.Products.FindOne(r.Context(), filter)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to retrieve product", "The product was updated, but could not be retrieved.").WithCause(err))
+		return
+	}
+	response.JSON(w, http.StatusOK, product)
 }
 
 // DeleteProduct untuk menghapus produk berdasarkan ID
-func DeleteProduct(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id := params["id"]
 
 	// Convert id string to ObjectID
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		response.WriteError(w, r, response.BadRequest("Invalid product ID", "Product ID format is incorrect."))
 		return
 	}
 
 	// Delete the product from the collection
-	result, err := productCollection.DeleteOne(context.TODO(), bson.M{"_id": objID})
+	before := h.repos.Products.Snapshot(r.Context(), bson.M{"_id": objID})
+	result, err := h.repos.Products.DeleteOne(r.Context(), bson.M{"_id": objID})
 	if err != nil || result.DeletedCount == 0 {
-		http.Error(w, "Product not found", http.StatusNotFound)
+		response.WriteError(w, r, response.NotFound("Product not found", "No product found with the specified ID."))
 		return
 	}
+	h.audit.Record(r, audit.Event{Action: "product.delete", Entity: "products", EntityID: objID.Hex(), Before: before})
 
 	w.WriteHeader(http.StatusNoContent) // Status 204 No Content
 }
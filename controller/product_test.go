@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUpdateProductKeepsFieldsThatWereNotSent(t *testing.T) {
+	h, repos := newTestHandler(t)
+	ctx := context.Background()
+	product := model.Product{ID: primitive.NewObjectID(), CategoryID: primitive.NewObjectID(), Name: "Botol", Rating: 4.5, Sold: 12, Stock: 30, Price: 15000}
+	if err := repos.Products.Insert(ctx, product); err != nil {
+		t.Fatalf("insert product: %v", err)
+	}
+
+	vars := map[string]string{"id": product.ID.Hex()}
+	status, body := callAs(t, model.RoleAdmin, h.UpdateProduct, http.MethodPut, "/products/"+product.ID.Hex(), vars, `{"price":17500}`)
+	if status != http.StatusOK {
+		t.Fatalf("update product: status %d, body %v", status, body)
+	}
+	if status, _ := callAs(t, model.RoleAdmin, h.UpdateProduct, http.MethodPut, "/products/"+product.ID.Hex(), vars, `{}`); status != http.StatusBadRequest {
+		t.Errorf("update without fields: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	updated, err := repos.Products.FindByID(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("find product: %v", err)
+	}
+	if updated.Price != 17500 || updated.Name != product.Name || updated.Rating != product.Rating || updated.Sold != product.Sold || updated.Stock != product.Stock {
+		t.Errorf("product = %+v, want the new price with the other fields unchanged", updated)
+	}
+}
@@ -1,7 +1,6 @@
 package controller
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -9,21 +8,23 @@ import (
 	"github.com/gorilla/mux" // Import gorilla/mux untuk menangani path parameters
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"plastiqu_co/config"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
 	"plastiqu_co/model"
 )
 
 // CreateReviewHandler untuk membuat ulasan baru
-func CreateReviewHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateReviewHandler(w http.ResponseWriter, r *http.Request) {
 	var review model.Review
 
 	// Decode request body ke struct Review
 	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded. Please check the structure of your request.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
+		return
+	}
+
+	if err := validate.Struct(review); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
@@ -35,81 +36,53 @@ func CreateReviewHandler(w http.ResponseWriter, r *http.Request) {
 	review.ID = primitive.NewObjectID()
 
 	// Simpan ulasan di database
-	collection := config.Mongoconn.Collection("reviews")
-	_, err := collection.InsertOne(context.TODO(), review)
+	collection := h.repos.Reviews
+	err := collection.Insert(r.Context(), review)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to create review",
-			"message": "An error occurred while creating the review.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to create review", "An error occurred while creating the review.").WithCause(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(review)
+	response.JSON(w, http.StatusCreated, review)
 }
 
 // GetReviewsHandler untuk mengambil semua ulasan produk
-func GetReviewsHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetReviewsHandler(w http.ResponseWriter, r *http.Request) {
 	productID := mux.Vars(r)["product_id"]
 	objID, err := primitive.ObjectIDFromHex(productID)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid product ID",
-			"message": "The provided product ID is not valid.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid product ID", "The provided product ID is not valid."))
 		return
 	}
 
 	// Query untuk mengambil ulasan berdasarkan productID
-	collection := config.Mongoconn.Collection("reviews")
-	cursor, err := collection.Find(context.TODO(), bson.M{"product_id": objID})
+	collection := h.repos.Reviews
+	reviews, err := collection.FindByProduct(r.Context(), objID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to fetch reviews",
-			"message": "An error occurred while fetching the reviews.",
-		})
-		return
-	}
-	defer cursor.Close(context.TODO())
-
-	var reviews []model.Review
-	if err := cursor.All(context.TODO(), &reviews); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to decode reviews",
-			"message": "An error occurred while decoding review data.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to fetch reviews", "An error occurred while fetching the reviews.").WithCause(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(reviews)
+	response.JSON(w, http.StatusOK, reviews)
 }
 
 // UpdateReviewHandler untuk memperbarui ulasan
-func UpdateReviewHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateReviewHandler(w http.ResponseWriter, r *http.Request) {
 	reviewID := mux.Vars(r)["review_id"]
 	objID, err := primitive.ObjectIDFromHex(reviewID)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid review ID",
-			"message": "The provided review ID is not valid.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid review ID", "The provided review ID is not valid."))
 		return
 	}
 
 	var review model.Review
 	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded. Please check the structure of your request.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
+		return
+	}
+
+	if err := validate.Patch(review); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
@@ -117,9 +90,9 @@ func UpdateReviewHandler(w http.ResponseWriter, r *http.Request) {
 	review.UpdatedAt = time.Now()
 
 	// Update ulasan di database
-	collection := config.Mongoconn.Collection("reviews")
+	collection := h.repos.Reviews
 	_, err = collection.UpdateOne(
-		context.TODO(),
+		r.Context(),
 		bson.M{"_id": objID},
 		bson.M{
 			"$set": bson.M{
@@ -131,74 +104,55 @@ func UpdateReviewHandler(w http.ResponseWriter, r *http.Request) {
 		},
 	)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to update review",
-			"message": "An error occurred while updating the review.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to update review", "An error occurred while updating the review.").WithCause(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Review updated successfully",
 	})
 }
 
 // DeleteReviewHandler untuk menghapus ulasan
-func DeleteReviewHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeleteReviewHandler(w http.ResponseWriter, r *http.Request) {
 	reviewID := mux.Vars(r)["review_id"]
 	objID, err := primitive.ObjectIDFromHex(reviewID)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid review ID",
-			"message": "The provided review ID is not valid.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid review ID", "The provided review ID is not valid."))
 		return
 	}
 
 	// Hapus ulasan dari database
-	collection := config.Mongoconn.Collection("reviews")
-	_, err = collection.DeleteOne(context.TODO(), bson.M{"_id": objID})
+	collection := h.repos.Reviews
+	_, err = collection.DeleteOne(r.Context(), bson.M{"_id": objID})
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to delete review",
-			"message": "An error occurred while deleting the review.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to delete review", "An error occurred while deleting the review.").WithCause(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Review deleted successfully",
 	})
 }
 
+// ReviewResponseRequest adalah body tanggapan admin atas ulasan
+type ReviewResponseRequest struct {
+	AdminResponse string `json:"admin_response"`
+}
+
 // AdminRespondReviewHandler untuk menanggapi ulasan
-func AdminRespondReviewHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) AdminRespondReviewHandler(w http.ResponseWriter, r *http.Request) {
 	reviewID := mux.Vars(r)["review_id"]
 	objID, err := primitive.ObjectIDFromHex(reviewID)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid review ID",
-			"message": "The provided review ID is not valid.",
-		})
+		response.WriteError(w, r, response.BadRequest("Invalid review ID", "The provided review ID is not valid."))
 		return
 	}
 
-	var response struct {
-		AdminResponse string `json:"admin_response"`
-	}
+	var request ReviewResponseRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded. Please check the structure of your request.",
-		})
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
 		return
 	}
 
@@ -206,28 +160,23 @@ func AdminRespondReviewHandler(w http.ResponseWriter, r *http.Request) {
 	responseDate := time.Now()
 
 	// Update tanggapan admin di ulasan
-	collection := config.Mongoconn.Collection("reviews")
+	collection := h.repos.Reviews
 	_, err = collection.UpdateOne(
-		context.TODO(),
+		r.Context(),
 		bson.M{"_id": objID},
 		bson.M{
 			"$set": bson.M{
-				"admin_response": response.AdminResponse,
+				"admin_response": request.AdminResponse,
 				"response_date":  responseDate,
 			},
 		},
 	)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to respond to review",
-			"message": "An error occurred while responding to the review.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to respond to review", "An error occurred while responding to the review.").WithCause(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Admin responded successfully",
 	})
 }
@@ -0,0 +1,380 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"time"
+
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/middleware"
+	"plastiqu_co/model"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+
+// RoleRequest adalah body untuk membuat dan memperbarui role
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	// RequireTwoFactor mewajibkan pengguna dengan role ini mendaftarkan TOTP
+	RequireTwoFactor bool `json:"require_two_factor"`
+}
+
+// invalidPermission mengembalikan permission pertama yang tidak dikenal, jika ada
+func invalidPermission(permissions []string) (string, bool) {
+	known := make(map[string]bool, len(model.Permissions))
+	for _, p := range model.Permissions {
+		known[p] = true
+	}
+	for _, p := range permissions {
+		if !known[p] {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// adminOnlyPermissions hanya dapat diberikan ke sebuah role oleh admin karena
+// pemegangnya dapat menaikkan hak aksesnya sendiri
+var adminOnlyPermissions = map[string]bool{
+	model.PermissionRolesManage:   true,
+	model.PermissionUsersManage:   true,
+	model.PermissionAPIKeysManage: true,
+}
+
+// checkGrant memastikan pemanggil boleh memberikan setiap permission ke sebuah role:
+// permission tersebut harus dimilikinya sendiri, dan permission di adminOnlyPermissions hanya boleh diberikan admin.
+func (h *Handler) checkGrant(r *http.Request, permissions []string) error {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		return response.Unauthorized("Unauthorized", "A valid bearer token is required.")
+	}
+	for _, p := range permissions {
+		if adminOnlyPermissions[p] && principal.Role != model.RoleAdmin {
+			return response.Forbidden("Access denied", "Only an admin can grant "+p+".")
+		}
+		allowed, err := h.guard.HasPermission(r.Context(), p)
+		if err != nil {
+			return response.Internal("Failed to check permission", "An error occurred while checking your permissions.").WithCause(err)
+		}
+		if !allowed {
+			return response.Forbidden("Access denied", "You cannot grant a permission you do not have: "+p+".")
+		}
+	}
+	return nil
+}
+
+// GetPermissions mengembalikan daftar permission yang dikenali sistem
+func (h *Handler) GetPermissions(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, model.Permissions)
+}
+
+// GetRoles mengambil semua role beserta permission-nya
+func (h *Handler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	roles, err := h.repos.Roles.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to retrieve roles", "An error occurred while retrieving roles.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, roles)
+}
+
+// CreateRole membuat role baru dengan sekumpulan permission
+func (h *Handler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	var request RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
+		return
+	}
+
+	if !roleNamePattern.MatchString(request.Name) {
+		response.WriteError(w, r, response.Validation("Invalid role name", "Role name must be 2-32 lowercase letters, digits or underscores and start with a letter."))
+		return
+	}
+	if p, ok := invalidPermission(request.Permissions); ok {
+		response.WriteError(w, r, response.Validation("Invalid permission", "Unknown permission: "+p))
+		return
+	}
+	if err := h.checkGrant(r, request.Permissions); err != nil {
+		response.WriteError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	collection := h.repos.Roles
+	count, err := collection.Count(ctx, bson.M{"name": request.Name})
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to create role", "An error occurred while creating the role.").WithCause(err))
+		return
+	}
+	if count > 0 {
+		response.WriteError(w, r, response.Conflict("Role already exists", "A role with this name already exists."))
+		return
+	}
+
+	if request.Permissions == nil {
+		request.Permissions = []string{}
+	}
+	role := model.Role{
+		ID:               primitive.NewObjectID(),
+		Name:             request.Name,
+		Description:      request.Description,
+		Permissions:      request.Permissions,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+		RequireTwoFactor: request.RequireTwoFactor,
+	}
+	err = collection.Insert(ctx, role)
+	if mongo.IsDuplicateKeyError(err) {
+		// Pembuatan bersamaan lolos pengecekan awal tapi ditolak oleh index unik roles.name
+		response.WriteError(w, r, response.Conflict("Role already exists", "A role with this name already exists."))
+		return
+	}
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to create role", "An error occurred while creating the role.").WithCause(err))
+		return
+	}
+	h.guard.InvalidateRoleCache(role.Name)
+	h.audit.Record(r, audit.Event{Action: "role.create", Entity: "roles", EntityID: role.ID.Hex(), After: role})
+
+	response.JSON(w, http.StatusCreated, map[string]interface{}{
+		"message": "Role created successfully",
+		"role":    role,
+	})
+}
+
+// UpdateRole memperbarui deskripsi, permission dan kebijakan 2FA sebuah role.
+// Permission role admin tidak dapat diubah, tetapi kebijakan 2FA-nya bisa, dan hanya oleh admin.
+func (h *Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	var request RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded. Please check the structure of your request."))
+		return
+	}
+	if p, ok := invalidPermission(request.Permissions); ok {
+		response.WriteError(w, r, response.Validation("Invalid permission", "Unknown permission: "+p))
+		return
+	}
+
+	fields := bson.M{
+		"description":        request.Description,
+		"require_two_factor": request.RequireTwoFactor,
+		"updated_at":         time.Now(),
+	}
+	if name == model.RoleAdmin {
+		if principal, ok := middleware.PrincipalFromContext(r.Context()); !ok || principal.Role != model.RoleAdmin {
+			response.WriteError(w, r, response.Forbidden("Access denied", "Only an admin can modify the admin role."))
+			return
+		}
+		if request.Permissions != nil {
+			response.WriteError(w, r, response.Forbidden("Access denied", "The admin role always has every permission and its permissions cannot be modified."))
+			return
+		}
+	} else {
+		if err := h.checkGrant(r, request.Permissions); err != nil {
+			response.WriteError(w, r, err)
+			return
+		}
+		if request.Permissions == nil {
+			request.Permissions = []string{}
+		}
+		fields["permissions"] = request.Permissions
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	collection := h.repos.Roles
+	before := collection.Snapshot(ctx, bson.M{"name": name})
+	result, err := collection.UpdateOne(ctx, bson.M{"name": name}, bson.M{"$set": fields})
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to update role", "An error occurred while updating the role.").WithCause(err))
+		return
+	}
+	if result.MatchedCount == 0 {
+		response.WriteError(w, r, response.NotFound("Role not found", "The role you are trying to update does not exist."))
+		return
+	}
+	h.guard.InvalidateRoleCache(name)
+	after := collection.Snapshot(ctx, bson.M{"name": name})
+	h.audit.Record(r, audit.Event{Action: "role.update", Entity: "roles", EntityID: name, Before: before, After: after})
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": "Role updated successfully",
+	})
+}
+
+// DeleteRole menghapus role yang bukan role bawaan dan tidak sedang dipakai pengguna
+func (h *Handler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	collection := h.repos.Roles
+	var role model.Role
+	role, err := collection.FindByName(ctx, name)
+	if err == mongo.ErrNoDocuments {
+		response.WriteError(w, r, response.NotFound("Role not found", "The role you are trying to delete does not exist."))
+		return
+	} else if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to delete role", "An error occurred while deleting the role.").WithCause(err))
+		return
+	}
+	if role.System {
+		response.WriteError(w, r, response.Forbidden("Access denied", "Built-in roles cannot be deleted."))
+		return
+	}
+
+	assigned, err := h.repos.Users.Count(ctx, bson.M{"role": name})
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to delete role", "An error occurred while deleting the role.").WithCause(err))
+		return
+	}
+	if assigned > 0 {
+		response.WriteError(w, r, response.Conflict("Role in use", "The role is still assigned to one or more users."))
+		return
+	}
+
+	if _, err := collection.DeleteOne(ctx, bson.M{"_id": role.ID}); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to delete role", "An error occurred while deleting the role.").WithCause(err))
+		return
+	}
+	h.guard.InvalidateRoleCache(name)
+	h.audit.Record(r, audit.Event{Action: "role.delete", Entity: "roles", EntityID: role.ID.Hex(), Before: role})
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": "Role deleted successfully",
+	})
+}
+
+// AssignRoleRequest adalah body untuk memberikan role kepada pengguna
+type AssignRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// AssignUserRole memberikan role tertentu kepada pengguna
+func (h *Handler) AssignUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid user ID", "User ID format is incorrect."))
+		return
+	}
+
+	var request AssignRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Role == "" {
+		response.WriteError(w, r, response.InvalidPayload("A role is required."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	count, err := h.repos.Roles.Count(ctx, bson.M{"name": request.Role})
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to update role", "An error occurred while updating the user's role.").WithCause(err))
+		return
+	}
+	if count == 0 {
+		response.WriteError(w, r, response.Validation("Role not found", "The role you are trying to assign does not exist."))
+		return
+	}
+
+	h.setUserRole(ctx, w, r, userID, request.Role, "User role updated successfully")
+}
+
+// RevokeUserRole mencabut role staf pengguna dan mengembalikannya menjadi role "user"
+func (h *Handler) RevokeUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		response.WriteError(w, r, response.BadRequest("Invalid user ID", "User ID format is incorrect."))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	h.setUserRole(ctx, w, r, userID, model.RoleUser, "User role updated successfully")
+}
+
+// checkRoleChange memastikan pemanggil boleh mengubah role pengguna userID dari from menjadi to.
+// Role sendiri tidak dapat diubah, dan hanya admin yang boleh memberikan atau mencabut role admin.
+func checkRoleChange(r *http.Request, userID primitive.ObjectID, from, to string) error {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		return response.Unauthorized("Unauthorized", "A valid bearer token is required.")
+	}
+	if principal.UserID == userID {
+		return response.Forbidden("Access denied", "You cannot change your own role.")
+	}
+	if (from == model.RoleAdmin || to == model.RoleAdmin) && principal.Role != model.RoleAdmin {
+		return response.Forbidden("Access denied", "Only an admin can grant or revoke the admin role.")
+	}
+	return nil
+}
+
+// setUserRole mengganti role pengguna lalu mencabut sesinya sehingga role baru langsung berlaku
+func (h *Handler) setUserRole(ctx context.Context, w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, role, message string) {
+	collection := h.repos.Users
+	user, err := collection.FindByID(ctx, userID)
+	if err == mongo.ErrNoDocuments {
+		response.WriteError(w, r, response.NotFound("User not found", "The user with the specified ID does not exist."))
+		return
+	} else if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to update role", "An error occurred while updating the user's role.").WithCause(err))
+		return
+	}
+	if err := checkRoleChange(r, userID, user.Role, role); err != nil {
+		response.WriteError(w, r, err)
+		return
+	}
+
+	before := collection.Snapshot(ctx, bson.M{"_id": userID})
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"role": role, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		response.WriteError(w, r, response.Internal("Failed to update role", "An error occurred while updating the user's role.").WithCause(err))
+		return
+	}
+	if result.MatchedCount == 0 {
+		response.WriteError(w, r, response.NotFound("User not found", "The user with the specified ID does not exist."))
+		return
+	}
+	h.audit.Record(r, audit.Event{
+		Action:   "user.role.update",
+		Entity:   "users",
+		EntityID: userID.Hex(),
+		Before:   before,
+		After:    collection.Snapshot(ctx, bson.M{"_id": userID}),
+	})
+
+	if err := h.auth.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		response.WriteError(w, r, response.Internal("Failed to revoke sessions", "The role was updated, but existing sessions could not be revoked.").WithCause(err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"message": message,
+		"role":    role,
+	})
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"plastiqu_co/controller/auth"
+	"plastiqu_co/helper/atdb"
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/mailer"
+	"plastiqu_co/middleware"
+	"plastiqu_co/model"
+	"plastiqu_co/repository"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTestHandler membuat Handler di atas MemoryDatabase yang sudah dimigrasi dan berisi role bawaan
+func newTestHandler(t *testing.T) (*Handler, *repository.Repositories) {
+	t.Helper()
+	repos := repository.New(atdb.NewMemoryDatabase())
+	if _, err := repos.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := repos.Roles.EnsureDefaults(context.Background()); err != nil {
+		t.Fatalf("seed roles: %v", err)
+	}
+	guard := middleware.NewGuard(repos)
+	recorder := audit.NewRecorder(repos.AuditLog)
+	authHandler := auth.NewHandler(repos, guard, recorder, auth.Mail{
+		Sender: mailer.OutboxSender{Dir: t.TempDir(), From: "no-reply@example.com"},
+	})
+	return NewHandler(repos, guard, recorder, authHandler, time.UTC), repos
+}
+
+// addRole menyimpan role langsung ke repository, melewati pengecekan handler
+func addRole(t *testing.T, repos *repository.Repositories, name string, permissions ...string) {
+	t.Helper()
+	role := model.Role{ID: primitive.NewObjectID(), Name: name, Permissions: permissions, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := repos.Roles.Insert(context.Background(), role); err != nil {
+		t.Fatalf("insert role %s: %v", name, err)
+	}
+}
+
+// callAs menjalankan handler sebagai principal dengan role tertentu dan mengembalikan status serta body respon
+func callAs(t *testing.T, role string, handler http.HandlerFunc, method, target string, vars map[string]string, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = mux.SetURLVars(req, vars)
+	principal := middleware.Principal{UserID: primitive.NewObjectID(), Role: role, SessionID: primitive.NewObjectID()}
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("%s %s: decode response %q: %v", method, target, rec.Body.String(), err)
+	}
+	return rec.Code, decoded
+}
+
+func TestCreateRoleLimitsGrantedPermissions(t *testing.T) {
+	h, repos := newTestHandler(t)
+	addRole(t, repos, "role_manager", model.PermissionRolesManage, model.PermissionUsersManage, model.PermissionCatalogWrite)
+
+	tests := []struct {
+		name   string
+		role   string
+		body   string
+		status int
+	}{
+		{"permission the caller has", "role_manager", `{"name":"catalog_editor","permissions":["catalog:write"]}`, http.StatusCreated},
+		{"permission the caller lacks", "role_manager", `{"name":"order_editor","permissions":["orders:write"]}`, http.StatusForbidden},
+		{"users:manage by a non-admin", "role_manager", `{"name":"user_manager","permissions":["users:manage"]}`, http.StatusForbidden},
+		{"roles:manage by a non-admin", "role_manager", `{"name":"role_manager_2","permissions":["roles:manage"]}`, http.StatusForbidden},
+		{"api_keys:manage by an admin", model.RoleAdmin, `{"name":"integrations","permissions":["api_keys:manage"]}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, body := callAs(t, tt.role, h.CreateRole, http.MethodPost, "/admin/roles", nil, tt.body); status != tt.status {
+				t.Fatalf("status %d, want %d (body %v)", status, tt.status, body)
+			}
+		})
+	}
+}
+
+func TestUpdateRoleLimitsGrantedPermissions(t *testing.T) {
+	h, repos := newTestHandler(t)
+	addRole(t, repos, "role_manager", model.PermissionRolesManage, model.PermissionUsersManage, model.PermissionOrdersRead)
+
+	tests := []struct {
+		name   string
+		role   string
+		target string
+		body   string
+		status int
+	}{
+		{"permission the caller has", "role_manager", model.RoleWarehouse, `{"permissions":["orders:read"]}`, http.StatusOK},
+		{"permission the caller lacks", "role_manager", model.RoleWarehouse, `{"permissions":["orders:read","payment_details:write"]}`, http.StatusForbidden},
+		{"users:manage by a non-admin", "role_manager", model.RoleWarehouse, `{"permissions":["users:manage"]}`, http.StatusForbidden},
+		{"admin 2FA policy by a non-admin", "role_manager", model.RoleAdmin, `{"require_two_factor":true}`, http.StatusForbidden},
+		{"admin 2FA policy by an admin", model.RoleAdmin, model.RoleAdmin, `{"require_two_factor":true}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := map[string]string{"name": tt.target}
+			if status, body := callAs(t, tt.role, h.UpdateRole, http.MethodPut, "/admin/roles/"+tt.target, vars, tt.body); status != tt.status {
+				t.Fatalf("status %d, want %d (body %v)", status, tt.status, body)
+			}
+		})
+	}
+
+	// Permintaan yang ditolak tidak mengubah role
+	role, err := repos.Roles.FindByName(context.Background(), model.RoleWarehouse)
+	if err != nil {
+		t.Fatalf("find role: %v", err)
+	}
+	if len(role.Permissions) != 1 || role.Permissions[0] != model.PermissionOrdersRead {
+		t.Errorf("warehouse permissions = %v, want [%s]", role.Permissions, model.PermissionOrdersRead)
+	}
+}
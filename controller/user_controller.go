@@ -4,160 +4,136 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
-	"plastiqu_co/config"
+	"plastiqu_co/controller/auth"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/validate"
+	"plastiqu_co/middleware"
 	"plastiqu_co/model"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// profileFields membangun $set untuk update profil. Sesuai validate.Patch, field yang
+// kosong dianggap tidak dikirim sehingga nilai yang tersimpan tidak tertimpa.
+func profileFields(updateData model.Users) bson.M {
+	fields := bson.M{"updated_at": time.Now()}
+	if updateData.Username != "" {
+		fields["username"] = updateData.Username
+	}
+	if updateData.Phone != "" {
+		fields["phone"] = updateData.Phone
+	}
+	if updateData.Image != "" {
+		fields["image"] = updateData.Image
+	}
+	return fields
+}
+
 // UpdateUserProfile allows a user to update their own profile
-func UpdateUserProfile(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string) // assuming you have middleware to get user_id from the JWT
+func (h *Handler) UpdateUserProfile(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.WriteError(w, r, response.Unauthorized("Unauthorized", "You must be logged in to perform this action."))
+		return
+	}
+	objID := principal.UserID
 	var updateData model.Users
 
 	// Decode the request body into updateData struct
 	err := json.NewDecoder(r.Body).Decode(&updateData)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
 		return
 	}
 
-	// Convert userID string to ObjectID
-	objID, err := primitive.ObjectIDFromHex(userID)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid user ID",
-			"message": "User ID format is incorrect.",
-		})
+	if err := validate.Patch(updateData); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
-	// Prepare the update fields
-	updateFields := bson.M{
-		"username": updateData.Username,
-		"phone":      updateData.Phone,
-		"image":      updateData.Image, // Nullable
-		"updated_at": time.Now(),
-	}
-
 	// Update the user's profile in the database
-	collection := config.Mongoconn.Collection("users")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	collection := h.repos.Users
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": updateFields})
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": profileFields(updateData)})
+	if fields, ok := auth.DuplicateKeyConflicts(err); ok {
+		response.WriteError(w, r, auth.ConflictError(fields))
+		return
+	}
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to update profile",
-			"message": "An error occurred while updating the profile.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to update profile", "An error occurred while updating the profile.").WithCause(err))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Profile updated successfully",
 	})
 }
 
+// ChangePasswordRequest is the body for changing the caller's own password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
 // ChangeUserPassword allows users to change their own password
-func ChangeUserPassword(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string)
-	var requestData struct {
-		CurrentPassword string `json:"current_password"`
-		NewPassword     string `json:"new_password"`
+func (h *Handler) ChangeUserPassword(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.WriteError(w, r, response.Unauthorized("Unauthorized", "You must be logged in to perform this action."))
+		return
 	}
+	objID := principal.UserID
+	var requestData ChangePasswordRequest
 
 	err := json.NewDecoder(r.Body).Decode(&requestData)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid request payload",
-			"message": "The JSON request body could not be decoded.",
-		})
+		response.WriteError(w, r, response.InvalidPayload("The JSON request body could not be decoded."))
 		return
 	}
 
-	// Convert userID string to ObjectID
-	objID, err := primitive.ObjectIDFromHex(userID)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Invalid user ID",
-			"message": "User ID format is incorrect.",
-		})
+	if err := validate.Struct(requestData); err != nil {
+		response.WriteError(w, r, err)
 		return
 	}
 
-	collection := config.Mongoconn.Collection("users")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	collection := h.repos.Users
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
 	// Get the user from the database
 	var user model.Users
-	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&user)
+	user, err = collection.FindOne(ctx, bson.M{"_id": objID})
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "User not found",
-			"message": "The user with the specified ID does not exist.",
-		})
+		response.WriteError(w, r, response.Internal("User not found", "The user with the specified ID does not exist.").WithCause(err))
 		return
 	}
 
 	// Check if the current password is correct
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(requestData.CurrentPassword))
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Incorrect current password",
-			"message": "The current password you provided is incorrect.",
-		})
+		response.WriteError(w, r, response.Unauthorized("Incorrect current password", "The current password you provided is incorrect."))
 		return
 	}
 
 	// Hash the new password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(requestData.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to hash password",
-			"message": "An error occurred while hashing the password.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to hash password", "An error occurred while hashing the password.").WithCause(err))
 		return
 	}
 
 	// Update the user's password
 	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"password": string(hashedPassword), "updated_at": time.Now()}})
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error":   "Failed to update password",
-			"message": "An error occurred while updating the password.",
-		})
+		response.WriteError(w, r, response.Internal("Failed to update password", "An error occurred while updating the password.").WithCause(err))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
+	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Password updated successfully",
 	})
 }
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUpdateProfileKeepsFieldsThatWereNotSent(t *testing.T) {
+	h, repos := newTestHandler(t)
+	ctx := context.Background()
+	id := primitive.NewObjectID()
+	user := model.Users{ID: id, Username: "ana", Email: "ana@example.com", Phone: "081234567890", Image: "https://example.com/ana.png", Role: model.RoleUser, Status: model.UserStatusActive}
+	if err := repos.Users.Insert(ctx, user); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+
+	status, body := callAs(t, model.RoleAdmin, h.AdminUpdateUserProfile, http.MethodPut, "/admin/update-user-profile?user_id="+id.Hex(), nil, `{"phone":"089876543210"}`)
+	if status != http.StatusOK {
+		t.Fatalf("update profile: status %d, body %v", status, body)
+	}
+
+	updated, err := repos.Users.FindByID(ctx, id)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if updated.Phone != "089876543210" || updated.Username != user.Username || updated.Image != user.Image {
+		t.Errorf("user = %q %q %q, want the new phone with the username and image unchanged", updated.Username, updated.Phone, updated.Image)
+	}
+}
@@ -1,6 +1,7 @@
 package atdb
 
 import (
+	"encoding/binary"
 	"plastiqu_co/helper/atapi"
 	"strconv"
 	"time"
@@ -9,45 +10,49 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GetDateSekarang() (datesekarang time.Time) {
-	// Definisi lokasi waktu sekarang
-	location, _ := time.LoadLocation("Asia/Jakarta")
+// boundaryID membuat ObjectID dengan timestamp t dan sisa byte nol untuk batas rentang _id.
+// NewObjectIDFromTimestamp mengisi sisanya dengan counter proses sehingga dokumen pada
+// detik pertama rentang bisa terlewat.
+func boundaryID(t time.Time) primitive.ObjectID {
+	var id primitive.ObjectID
+	binary.BigEndian.PutUint32(id[:4], uint32(t.Unix()))
+	return id
+}
 
-	t := time.Now().In(location) //.Truncate(24 * time.Hour)
+func GetDateSekarang(loc *time.Location) (datesekarang time.Time) {
+	t := time.Now().In(loc) //.Truncate(24 * time.Hour)
 	datesekarang = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 
 	return
 }
 
-func TodayFilter() bson.M {
+func TodayFilter(loc *time.Location) bson.M {
 	return bson.M{
-		"$gte": primitive.NewObjectIDFromTimestamp(GetDateSekarang()),
-		"$lt":  primitive.NewObjectIDFromTimestamp(GetDateSekarang().Add(24 * time.Hour)),
+		"$gte": boundaryID(GetDateSekarang(loc)),
+		"$lt":  boundaryID(GetDateSekarang(loc).Add(24 * time.Hour)),
 	}
 }
 
-func YesterdayNotLiburFilter() bson.M {
+func YesterdayNotLiburFilter(loc *time.Location) bson.M {
 	return bson.M{
-		"$gte": primitive.NewObjectIDFromTimestamp(GetDateKemarinBukanHariLibur()),
-		"$lt":  primitive.NewObjectIDFromTimestamp(GetDateKemarinBukanHariLibur().Add(24 * time.Hour)),
+		"$gte": boundaryID(GetDateKemarinBukanHariLibur(loc)),
+		"$lt":  boundaryID(GetDateKemarinBukanHariLibur(loc).Add(24 * time.Hour)),
 	}
 }
 
-func YesterdayFilter() bson.M {
+func YesterdayFilter(loc *time.Location) bson.M {
 	return bson.M{
-		"$gte": primitive.NewObjectIDFromTimestamp(GetDateKemarin()),
-		"$lt":  primitive.NewObjectIDFromTimestamp(GetDateKemarin().Add(24 * time.Hour)),
+		"$gte": boundaryID(GetDateKemarin(loc)),
+		"$lt":  boundaryID(GetDateKemarin(loc).Add(24 * time.Hour)),
 	}
 }
 
-func GetDateKemarinBukanHariLibur() (datekemarinbukanlibur time.Time) {
-	// Definisi lokasi waktu sekarang
-	location, _ := time.LoadLocation("Asia/Jakarta")
+func GetDateKemarinBukanHariLibur(loc *time.Location) (datekemarinbukanlibur time.Time) {
 	n := -1
-	t := time.Now().AddDate(0, 0, n).In(location) //.Truncate(24 * time.Hour)
+	t := time.Now().AddDate(0, 0, n).In(loc) //.Truncate(24 * time.Hour)
 	for HariLibur(t) {
 		n -= 1
-		t = time.Now().AddDate(0, 0, n).In(location)
+		t = time.Now().AddDate(0, 0, n).In(loc)
 	}
 
 	datekemarinbukanlibur = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
@@ -55,11 +60,9 @@ func GetDateKemarinBukanHariLibur() (datekemarinbukanlibur time.Time) {
 	return
 }
 
-func GetDateKemarin() (datekemarin time.Time) {
-	// Definisi lokasi waktu sekarang
-	location, _ := time.LoadLocation("Asia/Jakarta")
+func GetDateKemarin(loc *time.Location) (datekemarin time.Time) {
 	n := -1
-	t := time.Now().AddDate(0, 0, n).In(location) //.Truncate(24 * time.Hour)
+	t := time.Now().AddDate(0, 0, n).In(loc) //.Truncate(24 * time.Hour)
 	datekemarin = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 
 	return
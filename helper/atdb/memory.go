@@ -0,0 +1,460 @@
+package atdb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MemoryDatabase adalah Database yang disimpan di memori proses, untuk pengujian dan
+// pengembangan lokal tanpa MongoDB. Data hilang saat proses berhenti.
+//
+// Filter yang didukung: kesamaan (termasuk field bertitik dan elemen array), $eq, $ne,
+// $gt, $gte, $lt, $lte, $in, $nin, $exists, $regex, $and, $or dan $nor. Update yang
+// didukung: $set, $unset, $setOnInsert, $inc, $push dan $pull. Index unik (termasuk
+// partial filter dan collation case-insensitive) ikut ditegakkan.
+type MemoryDatabase struct {
+	mu          sync.Mutex
+	collections map[string]*memoryCollection
+}
+
+// NewMemoryDatabase membuat MemoryDatabase kosong
+func NewMemoryDatabase() *MemoryDatabase {
+	return &MemoryDatabase{collections: map[string]*memoryCollection{}}
+}
+
+func (m *MemoryDatabase) Collection(name string) Collection {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	coll, ok := m.collections[name]
+	if !ok {
+		coll = &memoryCollection{name: name}
+		m.collections[name] = coll
+	}
+	return coll
+}
+
+func (m *MemoryDatabase) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *MemoryDatabase) Disconnect(ctx context.Context) error {
+	return nil
+}
+
+type memoryCollection struct {
+	mu      sync.RWMutex
+	name    string
+	docs    []bson.M
+	indexes []memoryIndex
+}
+
+// memoryIndex adalah index unik yang ditegakkan saat insert dan update
+type memoryIndex struct {
+	name    string
+	keys    []string
+	fold    bool
+	partial bson.M
+}
+
+func (c *memoryCollection) Name() string {
+	return c.name
+}
+
+func (c *memoryCollection) FindOne(ctx context.Context, filter, result interface{}, opts ...*options.FindOneOptions) error {
+	opt := options.MergeFindOneOptions(opts...)
+	findOpts := options.Find().SetLimit(1)
+	findOpts.Sort = opt.Sort
+	findOpts.Skip = opt.Skip
+	findOpts.Projection = opt.Projection
+	findOpts.Collation = opt.Collation
+
+	docs, err := c.find(filter, findOpts)
+	if err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return decodeDocument(docs[0], result)
+}
+
+func (c *memoryCollection) Find(ctx context.Context, filter, results interface{}, opts ...*options.FindOptions) error {
+	docs, err := c.find(filter, options.MergeFindOptions(opts...))
+	if err != nil {
+		return err
+	}
+
+	target := reflect.ValueOf(results)
+	if target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("memory store: results must be a pointer to a slice, got %T", results)
+	}
+	slice := reflect.MakeSlice(target.Elem().Type(), 0, len(docs))
+	for _, doc := range docs {
+		elem := reflect.New(slice.Type().Elem())
+		if err := decodeDocument(doc, elem.Interface()); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem.Elem())
+	}
+	target.Elem().Set(slice)
+	return nil
+}
+
+func (c *memoryCollection) find(filter interface{}, opt *options.FindOptions) ([]bson.M, error) {
+	query, err := toBSON(filter)
+	if err != nil {
+		return nil, err
+	}
+	fold := foldCase(opt.Collation)
+
+	c.mu.RLock()
+	var docs []bson.M
+	for _, doc := range c.docs {
+		ok, err := matches(doc, query, fold)
+		if err != nil {
+			c.mu.RUnlock()
+			return nil, err
+		}
+		if ok {
+			docs = append(docs, doc)
+		}
+	}
+	c.mu.RUnlock()
+
+	if opt.Sort != nil {
+		if err := sortDocuments(docs, opt.Sort, fold); err != nil {
+			return nil, err
+		}
+	}
+	docs = paginate(docs, opt.Skip, opt.Limit)
+	if opt.Projection != nil {
+		if docs, err = project(docs, opt.Projection); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+func (c *memoryCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	opt := options.MergeCountOptions(opts...)
+	docs, err := c.find(filter, &options.FindOptions{Collation: opt.Collation, Skip: opt.Skip, Limit: opt.Limit})
+	return int64(len(docs)), err
+}
+
+func (c *memoryCollection) InsertOne(ctx context.Context, document interface{}) (interface{}, error) {
+	doc, err := toBSON(document)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := doc["_id"]; !ok {
+		doc["_id"] = primitive.NewObjectID()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.checkUnique(doc, -1); err != nil {
+		return nil, err
+	}
+	c.docs = append(c.docs, doc)
+	return doc["_id"], nil
+}
+
+func (c *memoryCollection) UpdateOne(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	opt := options.MergeUpdateOptions(opts...)
+	result, _, _, err := c.update(filter, update, false, opt.Upsert != nil && *opt.Upsert, opt.Collation)
+	return result, err
+}
+
+func (c *memoryCollection) UpdateMany(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	opt := options.MergeUpdateOptions(opts...)
+	result, _, _, err := c.update(filter, update, true, opt.Upsert != nil && *opt.Upsert, opt.Collation)
+	return result, err
+}
+
+func (c *memoryCollection) FindOneAndUpdate(ctx context.Context, filter, update, result interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	opt := options.MergeFindOneAndUpdateOptions(opts...)
+	_, before, after, err := c.update(filter, update, false, opt.Upsert != nil && *opt.Upsert, opt.Collation)
+	if err != nil {
+		return err
+	}
+
+	doc := before
+	if opt.ReturnDocument != nil && *opt.ReturnDocument == options.After {
+		doc = after
+	}
+	if doc == nil {
+		return mongo.ErrNoDocuments
+	}
+	if opt.Projection != nil {
+		projected, err := project([]bson.M{doc}, opt.Projection)
+		if err != nil {
+			return err
+		}
+		doc = projected[0]
+	}
+	return decodeDocument(doc, result)
+}
+
+// update menerapkan perubahan ke dokumen yang cocok dengan filter. before dan after adalah
+// dokumen pertama yang terkena, sebelum dan sesudah diubah; before kosong untuk hasil upsert.
+func (c *memoryCollection) update(filter, update interface{}, many, upsert bool, collation *options.Collation) (result *mongo.UpdateResult, before, after bson.M, err error) {
+	query, err := toBSON(filter)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	changes, err := toBSON(update)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fold := foldCase(collation)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result = &mongo.UpdateResult{}
+	for i, doc := range c.docs {
+		ok, err := matches(doc, query, fold)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !ok {
+			continue
+		}
+		result.MatchedCount++
+
+		updated := cloneDocument(doc)
+		if err := applyUpdate(updated, changes, false); err != nil {
+			return nil, nil, nil, err
+		}
+		if !reflect.DeepEqual(doc, updated) {
+			if err := c.checkUnique(updated, i); err != nil {
+				return nil, nil, nil, err
+			}
+			c.docs[i] = updated
+			result.ModifiedCount++
+		}
+		if before == nil {
+			before, after = doc, updated
+		}
+		if !many {
+			break
+		}
+	}
+
+	if result.MatchedCount == 0 && upsert {
+		doc := upsertBase(query)
+		if err := applyUpdate(doc, changes, true); err != nil {
+			return nil, nil, nil, err
+		}
+		if _, ok := doc["_id"]; !ok {
+			doc["_id"] = primitive.NewObjectID()
+		}
+		if err := c.checkUnique(doc, -1); err != nil {
+			return nil, nil, nil, err
+		}
+		c.docs = append(c.docs, doc)
+		result.UpsertedCount = 1
+		result.UpsertedID = doc["_id"]
+		after = doc
+	}
+	return result, before, after, nil
+}
+
+func (c *memoryCollection) DeleteOne(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error) {
+	return c.delete(filter, false)
+}
+
+func (c *memoryCollection) DeleteMany(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error) {
+	return c.delete(filter, true)
+}
+
+func (c *memoryCollection) delete(filter interface{}, many bool) (*mongo.DeleteResult, error) {
+	query, err := toBSON(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := &mongo.DeleteResult{}
+	kept := c.docs[:0:0]
+	for _, doc := range c.docs {
+		if many || result.DeletedCount == 0 {
+			ok, err := matches(doc, query, false)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result.DeletedCount++
+				continue
+			}
+		}
+		kept = append(kept, doc)
+	}
+	c.docs = kept
+	return result, nil
+}
+
+// CreateIndexes hanya mencatat index unik; index lain tidak berpengaruh di memori
+func (c *memoryCollection) CreateIndexes(ctx context.Context, models []mongo.IndexModel) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, model := range models {
+		keys, err := toOrderedBSON(model.Keys)
+		if err != nil {
+			return err
+		}
+		opt := model.Options
+		if opt == nil || opt.Unique == nil || !*opt.Unique {
+			continue
+		}
+
+		index := memoryIndex{fold: foldCase(opt.Collation)}
+		var names []string
+		for _, key := range keys {
+			index.keys = append(index.keys, key.Key)
+			names = append(names, fmt.Sprintf("%s_%v", key.Key, key.Value))
+		}
+		index.name = strings.Join(names, "_")
+		if opt.Name != nil {
+			index.name = *opt.Name
+		}
+		if opt.PartialFilterExpression != nil {
+			if index.partial, err = toBSON(opt.PartialFilterExpression); err != nil {
+				return err
+			}
+		}
+		c.indexes = append(c.indexes, index)
+	}
+	return nil
+}
+
+// checkUnique memastikan doc tidak melanggar index unik. skip adalah posisi doc itu sendiri
+// saat update, atau -1 saat insert.
+func (c *memoryCollection) checkUnique(doc bson.M, skip int) error {
+	indexes := append([]memoryIndex{{name: "_id_", keys: []string{"_id"}}}, c.indexes...)
+	for _, index := range indexes {
+		if ok, _ := matches(doc, index.partial, false); !ok {
+			continue
+		}
+		for i, other := range c.docs {
+			if i == skip {
+				continue
+			}
+			if ok, _ := matches(other, index.partial, false); !ok {
+				continue
+			}
+			duplicate := true
+			for _, key := range index.keys {
+				a, _ := lookup(doc, key)
+				b, _ := lookup(other, key)
+				if !equalValues(first(a), first(b), index.fold) {
+					duplicate = false
+					break
+				}
+			}
+			if duplicate {
+				return duplicateKeyError(c.name, index.name)
+			}
+		}
+	}
+	return nil
+}
+
+// duplicateKeyError meniru error E11000 dari MongoDB sehingga mongo.IsDuplicateKeyError tetap berlaku
+func duplicateKeyError(collection, index string) error {
+	return mongo.WriteException{WriteErrors: mongo.WriteErrors{{
+		Code:    11000,
+		Message: fmt.Sprintf("E11000 duplicate key error collection: %s index: %s", collection, index),
+	}}}
+}
+
+// upsertBase mengambil field kesamaan dari filter sebagai isi awal dokumen hasil upsert
+func upsertBase(query bson.M) bson.M {
+	doc := bson.M{}
+	for key, value := range query {
+		if strings.HasPrefix(key, "$") || isOperatorDocument(value) {
+			continue
+		}
+		if _, isRegex := value.(primitive.Regex); isRegex {
+			continue
+		}
+		setPath(doc, key, value)
+	}
+	return doc
+}
+
+func foldCase(collation *options.Collation) bool {
+	return collation != nil && collation.Strength > 0 && collation.Strength < 3
+}
+
+func paginate(docs []bson.M, skip, limit *int64) []bson.M {
+	if skip != nil && *skip > 0 {
+		if int(*skip) >= len(docs) {
+			return nil
+		}
+		docs = docs[*skip:]
+	}
+	if limit != nil && *limit > 0 && int(*limit) < len(docs) {
+		docs = docs[:*limit]
+	}
+	return docs
+}
+
+// toBSON mengubah filter, update atau dokumen (struct, map, bson.D) menjadi bson.M dengan
+// tipe nilai yang seragam, sama seperti yang akan tersimpan di MongoDB
+func toBSON(v interface{}) (bson.M, error) {
+	if v == nil {
+		return bson.M{}, nil
+	}
+	if _, ok := v.(mongo.Pipeline); ok {
+		return nil, fmt.Errorf("memory store: aggregation pipeline updates are not supported")
+	}
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	doc := bson.M{}
+	err = bson.Unmarshal(data, &doc)
+	return doc, err
+}
+
+// toOrderedBSON seperti toBSON tetapi mempertahankan urutan field, untuk sort dan key index
+func toOrderedBSON(v interface{}) (bson.D, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.D
+	err = bson.Unmarshal(data, &doc)
+	return doc, err
+}
+
+func cloneDocument(doc bson.M) bson.M {
+	clone, err := toBSON(doc)
+	if err != nil {
+		panic(err) // Dokumen yang tersimpan selalu dapat di-marshal ulang
+	}
+	return clone
+}
+
+func decodeDocument(doc bson.M, result interface{}) error {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(data, result)
+}
@@ -0,0 +1,553 @@
+package atdb
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// matches mengevaluasi filter MongoDB terhadap satu dokumen. fold bernilai true untuk
+// collation case-insensitive.
+func matches(doc, query bson.M, fold bool) (bool, error) {
+	for key, cond := range query {
+		var ok bool
+		var err error
+		switch key {
+		case "$and", "$or", "$nor":
+			ok, err = matchLogical(doc, key, cond, fold)
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("memory store: unsupported query operator %s", key)
+			}
+			values, found := lookup(doc, key)
+			ok, err = matchField(values, found, cond, fold)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchLogical(doc bson.M, op string, cond interface{}, fold bool) (bool, error) {
+	clauses, ok := cond.(bson.A)
+	if !ok {
+		return false, fmt.Errorf("memory store: %s requires an array", op)
+	}
+	for _, clause := range clauses {
+		query, ok := asDocument(clause)
+		if !ok {
+			return false, fmt.Errorf("memory store: %s requires an array of documents", op)
+		}
+		ok, err := matches(doc, query, fold)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case op == "$and" && !ok:
+			return false, nil
+		case op == "$or" && ok:
+			return true, nil
+		case op == "$nor" && ok:
+			return false, nil
+		}
+	}
+	return op != "$or", nil
+}
+
+// matchField mengevaluasi kondisi satu field. values berisi nilai field; untuk path yang
+// melewati array isinya bisa lebih dari satu.
+func matchField(values []interface{}, found bool, cond interface{}, fold bool) (bool, error) {
+	if regex, ok := cond.(primitive.Regex); ok {
+		return matchRegex(values, regex.Pattern, regex.Options)
+	}
+	if !isOperatorDocument(cond) {
+		return matchEqual(values, found, cond, fold), nil
+	}
+
+	ops, _ := asDocument(cond)
+	for op, operand := range ops {
+		var ok bool
+		var err error
+		switch op {
+		case "$eq":
+			ok = matchEqual(values, found, operand, fold)
+		case "$ne":
+			ok = !matchEqual(values, found, operand, fold)
+		case "$gt", "$gte", "$lt", "$lte":
+			ok = matchCompare(values, op, operand, fold)
+		case "$in", "$nin":
+			list, isArray := operand.(bson.A)
+			if !isArray {
+				return false, fmt.Errorf("memory store: %s requires an array", op)
+			}
+			for _, item := range list {
+				if matchEqual(values, found, item, fold) {
+					ok = true
+					break
+				}
+			}
+			if op == "$nin" {
+				ok = !ok
+			}
+		case "$exists":
+			ok = found == truthy(operand)
+		case "$regex":
+			pattern, _ := operand.(string)
+			options, _ := ops["$options"].(string)
+			if regex, isRegex := operand.(primitive.Regex); isRegex {
+				pattern, options = regex.Pattern, regex.Options
+			}
+			ok, err = matchRegex(values, pattern, options)
+		case "$options":
+			ok = true
+		default:
+			return false, fmt.Errorf("memory store: unsupported query operator %s", op)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchEqual(values []interface{}, found bool, target interface{}, fold bool) bool {
+	if !found {
+		return target == nil
+	}
+	for _, value := range values {
+		if equalValues(value, target, fold) {
+			return true
+		}
+		if items, ok := value.(bson.A); ok {
+			for _, item := range items {
+				if equalValues(item, target, fold) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func matchCompare(values []interface{}, op string, target interface{}, fold bool) bool {
+	for _, value := range expand(values) {
+		result, ok := compareValues(value, target, fold)
+		if !ok {
+			continue
+		}
+		switch {
+		case op == "$gt" && result > 0,
+			op == "$gte" && result >= 0,
+			op == "$lt" && result < 0,
+			op == "$lte" && result <= 0:
+			return true
+		}
+	}
+	return false
+}
+
+func matchRegex(values []interface{}, pattern, options string) (bool, error) {
+	var flags string
+	for _, option := range options {
+		if strings.ContainsRune("ims", option) {
+			flags += string(option)
+		}
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	for _, value := range expand(values) {
+		if s, ok := value.(string); ok && re.MatchString(s) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// expand meratakan nilai array satu tingkat, seperti MongoDB saat membandingkan field array
+func expand(values []interface{}) []interface{} {
+	var out []interface{}
+	for _, value := range values {
+		if items, ok := value.(bson.A); ok {
+			out = append(out, items...)
+			continue
+		}
+		out = append(out, value)
+	}
+	return out
+}
+
+// lookup mengambil nilai pada path bertitik, mis. "two_factor.last_step". Path yang melewati
+// array menghasilkan nilai dari setiap elemen.
+func lookup(doc bson.M, path string) ([]interface{}, bool) {
+	current := []interface{}{doc}
+	for _, part := range strings.Split(path, ".") {
+		var next []interface{}
+		for _, value := range current {
+			if sub, ok := asDocument(value); ok {
+				if v, exists := sub[part]; exists {
+					next = append(next, v)
+				}
+				continue
+			}
+			items, ok := value.(bson.A)
+			if !ok {
+				continue
+			}
+			if index, err := strconv.Atoi(part); err == nil {
+				if index >= 0 && index < len(items) {
+					next = append(next, items[index])
+				}
+				continue
+			}
+			for _, item := range items {
+				if sub, ok := asDocument(item); ok {
+					if v, exists := sub[part]; exists {
+						next = append(next, v)
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current, len(current) > 0
+}
+
+func first(values []interface{}) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+func equalValues(a, b interface{}, fold bool) bool {
+	if result, ok := compareValues(a, b, fold); ok {
+		return result == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compareValues membandingkan dua nilai skalar dengan tipe sejenis. ok bernilai false jika
+// tipe keduanya tidak dapat dibandingkan.
+func compareValues(a, b interface{}, fold bool) (result int, ok bool) {
+	if x, isNumber := toFloat(a); isNumber {
+		if y, isNumber := toFloat(b); isNumber {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+		return 0, false
+	}
+
+	switch x := a.(type) {
+	case nil:
+		if b == nil {
+			return 0, true
+		}
+	case string:
+		if y, isString := b.(string); isString {
+			if fold {
+				x, y = strings.ToLower(x), strings.ToLower(y)
+			}
+			return strings.Compare(x, y), true
+		}
+	case primitive.ObjectID:
+		if y, isID := b.(primitive.ObjectID); isID {
+			return bytes.Compare(x[:], y[:]), true
+		}
+	case primitive.DateTime:
+		if y, isDate := b.(primitive.DateTime); isDate {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	case bool:
+		if y, isBool := b.(bool); isBool {
+			switch {
+			case x == y:
+				return 0, true
+			case !x:
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+func toInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func truthy(v interface{}) bool {
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	if n, ok := toFloat(v); ok {
+		return n != 0
+	}
+	return v != nil
+}
+
+func asDocument(v interface{}) (bson.M, bool) {
+	switch doc := v.(type) {
+	case bson.M:
+		return doc, true
+	case bson.D:
+		return doc.Map(), true
+	}
+	return nil, false
+}
+
+// isOperatorDocument bernilai true untuk kondisi seperti {"$gte": ...}
+func isOperatorDocument(v interface{}) bool {
+	doc, ok := asDocument(v)
+	if !ok || len(doc) == 0 {
+		return false
+	}
+	for key := range doc {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// applyUpdate menerapkan operator update ke doc. insert bernilai true saat dokumen
+// dibuat oleh upsert sehingga $setOnInsert ikut diterapkan.
+func applyUpdate(doc, update bson.M, insert bool) error {
+	if len(update) == 0 || !isOperatorDocument(update) {
+		return fmt.Errorf("memory store: update must only contain operators such as $set")
+	}
+	for op, value := range update {
+		fields, ok := asDocument(value)
+		if !ok {
+			return fmt.Errorf("memory store: %s requires a document", op)
+		}
+		for path, operand := range fields {
+			switch op {
+			case "$set":
+				setPath(doc, path, operand)
+			case "$setOnInsert":
+				if insert {
+					setPath(doc, path, operand)
+				}
+			case "$unset":
+				unsetPath(doc, path)
+			case "$inc":
+				values, _ := lookup(doc, path)
+				sum, isNumber := increment(first(values), operand)
+				if !isNumber {
+					return fmt.Errorf("memory store: $inc requires a number for %s", path)
+				}
+				setPath(doc, path, sum)
+			case "$push":
+				values, _ := lookup(doc, path)
+				items, _ := first(values).(bson.A)
+				setPath(doc, path, append(append(bson.A{}, items...), operand))
+			case "$pull":
+				values, _ := lookup(doc, path)
+				items, _ := first(values).(bson.A)
+				kept := bson.A{}
+				for _, item := range items {
+					if !pullMatches(item, operand) {
+						kept = append(kept, item)
+					}
+				}
+				if items != nil {
+					setPath(doc, path, kept)
+				}
+			default:
+				return fmt.Errorf("memory store: unsupported update operator %s", op)
+			}
+		}
+	}
+	return nil
+}
+
+// increment menjumlahkan seperti $inc MongoDB: hasil tetap bilangan bulat jika kedua nilai
+// bilangan bulat (int32 selama muat, selain itu int64), dan double jika salah satunya double.
+// Field yang belum ada dianggap nol.
+func increment(current, delta interface{}) (interface{}, bool) {
+	if current == nil {
+		current = int32(0)
+	}
+	a, aInt := toInt(current)
+	b, bInt := toInt(delta)
+	if aInt && bInt {
+		sum := a + b
+		_, a32 := current.(int32)
+		_, b32 := delta.(int32)
+		if a32 && b32 && sum >= math.MinInt32 && sum <= math.MaxInt32 {
+			return int32(sum), true
+		}
+		return sum, true
+	}
+	x, xNumber := toFloat(current)
+	y, yNumber := toFloat(delta)
+	if !xNumber || !yNumber {
+		return nil, false
+	}
+	return x + y, true
+}
+
+// pullMatches mengecek apakah elemen array harus dihapus oleh $pull
+func pullMatches(item, cond interface{}) bool {
+	if isOperatorDocument(cond) {
+		ok, _ := matchField([]interface{}{item}, true, cond, false)
+		return ok
+	}
+	if query, isQuery := asDocument(cond); isQuery {
+		if sub, isDoc := asDocument(item); isDoc {
+			ok, _ := matches(sub, query, false)
+			return ok
+		}
+	}
+	return equalValues(item, cond, false)
+}
+
+func setPath(doc bson.M, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		sub, ok := asDocument(doc[part])
+		if !ok {
+			sub = bson.M{}
+		}
+		doc[part] = sub
+		doc = sub
+	}
+	doc[parts[len(parts)-1]] = value
+}
+
+func unsetPath(doc bson.M, path string) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		sub, ok := asDocument(doc[part])
+		if !ok {
+			return
+		}
+		doc = sub
+	}
+	delete(doc, parts[len(parts)-1])
+}
+
+// sortDocuments mengurutkan dokumen sesuai spesifikasi sort MongoDB, mis. {"created_at": -1}
+func sortDocuments(docs []bson.M, spec interface{}, fold bool) error {
+	keys, err := toOrderedBSON(spec)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, key := range keys {
+			a, _ := lookup(docs[i], key.Key)
+			b, _ := lookup(docs[j], key.Key)
+			result := compareForSort(first(a), first(b), fold)
+			if result == 0 {
+				continue
+			}
+			if direction, _ := toFloat(key.Value); direction < 0 {
+				return result > 0
+			}
+			return result < 0
+		}
+		return false
+	})
+	return nil
+}
+
+// compareForSort seperti compareValues, tetapi nilai kosong selalu di depan dan tipe
+// berbeda diurutkan berdasarkan nama tipenya agar hasilnya tetap stabil
+func compareForSort(a, b interface{}, fold bool) int {
+	if result, ok := compareValues(a, b, fold); ok {
+		return result
+	}
+	switch {
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return strings.Compare(fmt.Sprintf("%T", a), fmt.Sprintf("%T", b))
+}
+
+// project menerapkan projection tingkat atas, baik inklusi ({"name": 1}) maupun eksklusi ({"password": 0})
+func project(docs []bson.M, projection interface{}) ([]bson.M, error) {
+	spec, err := toBSON(projection)
+	if err != nil || len(spec) == 0 {
+		return docs, err
+	}
+
+	include := false
+	for field, value := range spec {
+		if field != "_id" && truthy(value) {
+			include = true
+		}
+	}
+
+	projected := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		out := bson.M{}
+		if include {
+			for field, value := range spec {
+				if v, ok := doc[field]; ok && truthy(value) {
+					out[field] = v
+				}
+			}
+			if v, ok := spec["_id"]; !ok || truthy(v) {
+				out["_id"] = doc["_id"]
+			}
+		} else {
+			for field, value := range doc {
+				out[field] = value
+			}
+			for field := range spec {
+				delete(out, field)
+			}
+		}
+		projected = append(projected, out)
+	}
+	return projected, nil
+}
@@ -0,0 +1,219 @@
+package atdb
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// seed mengisi koleksi baru dengan dokumen yang diberikan
+func seed(t *testing.T, docs ...bson.M) Collection {
+	t.Helper()
+	coll := NewMemoryDatabase().Collection("items")
+	for _, doc := range docs {
+		if _, err := coll.InsertOne(context.Background(), doc); err != nil {
+			t.Fatalf("insert %v: %v", doc, err)
+		}
+	}
+	return coll
+}
+
+// names mengembalikan field name dari hasil Find, terurut
+func names(t *testing.T, coll Collection, filter interface{}) []string {
+	t.Helper()
+	var docs []struct {
+		Name string `bson:"name"`
+	}
+	if err := coll.Find(context.Background(), filter, &docs); err != nil {
+		t.Fatalf("find %v: %v", filter, err)
+	}
+	result := []string{}
+	for _, doc := range docs {
+		result = append(result, doc.Name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestMemoryFind(t *testing.T) {
+	loc := time.FixedZone("WIB", 7*60*60)
+	today := GetDateSekarang(loc)
+	coll := seed(t,
+		bson.M{"_id": primitive.NewObjectIDFromTimestamp(today.Add(-time.Second)), "name": "yesterday", "status": "active", "tags": bson.A{"a", "b"}, "address": bson.M{"city": "Bandung"}},
+		bson.M{"_id": primitive.NewObjectIDFromTimestamp(today), "name": "midnight", "status": "suspended", "tags": bson.A{"b"}},
+		bson.M{"_id": primitive.NewObjectIDFromTimestamp(today.Add(23 * time.Hour)), "name": "evening", "status": "active", "address": bson.M{"city": "Jakarta"}},
+		bson.M{"_id": primitive.NewObjectIDFromTimestamp(today.Add(24 * time.Hour)), "name": "tomorrow"},
+	)
+
+	tests := []struct {
+		name   string
+		filter bson.M
+		want   []string
+	}{
+		{"equality", bson.M{"status": "active"}, []string{"evening", "yesterday"}},
+		{"equality on a missing field matches nil", bson.M{"status": nil}, []string{"tomorrow"}},
+		{"dotted field", bson.M{"address.city": "Jakarta"}, []string{"evening"}},
+		{"array element", bson.M{"tags": "b"}, []string{"midnight", "yesterday"}},
+		{"today filter on _id", bson.M{"_id": TodayFilter(loc)}, []string{"evening", "midnight"}},
+		{"$in", bson.M{"status": bson.M{"$in": bson.A{"suspended", "deleted"}}}, []string{"midnight"}},
+		{"$nin includes missing fields", bson.M{"status": bson.M{"$nin": bson.A{"active", "suspended"}}}, []string{"tomorrow"}},
+		{"$exists", bson.M{"tags": bson.M{"$exists": true}}, []string{"midnight", "yesterday"}},
+		{"$or", bson.M{"$or": bson.A{bson.M{"name": "tomorrow"}, bson.M{"address.city": "Bandung"}}}, []string{"tomorrow", "yesterday"}},
+		{"$regex case-insensitive", bson.M{"name": bson.M{"$regex": "^EVE", "$options": "i"}}, []string{"evening"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := names(t, coll, tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    bson.M
+		update bson.M
+		want   bson.M
+	}{
+		{
+			name:   "$inc keeps integers",
+			doc:    bson.M{"count": int32(2)},
+			update: bson.M{"$inc": bson.M{"count": 3}},
+			want:   bson.M{"count": int32(5)},
+		},
+		{
+			name:   "$inc on a missing field starts at zero",
+			doc:    bson.M{},
+			update: bson.M{"$inc": bson.M{"count": 1}},
+			want:   bson.M{"count": int32(1)},
+		},
+		{
+			name:   "$inc with a double",
+			doc:    bson.M{"total": int32(1)},
+			update: bson.M{"$inc": bson.M{"total": 0.5}},
+			want:   bson.M{"total": 1.5},
+		},
+		{
+			name:   "$pull by value",
+			doc:    bson.M{"tags": bson.A{"a", "b", "a"}},
+			update: bson.M{"$pull": bson.M{"tags": "a"}},
+			want:   bson.M{"tags": bson.A{"b"}},
+		},
+		{
+			name:   "$pull by query",
+			doc:    bson.M{"items": bson.A{bson.M{"sku": "x", "qty": int32(1)}, bson.M{"sku": "y", "qty": int32(5)}}},
+			update: bson.M{"$pull": bson.M{"items": bson.M{"qty": bson.M{"$lt": 3}}}},
+			want:   bson.M{"items": bson.A{bson.M{"sku": "y", "qty": int32(5)}}},
+		},
+		{
+			name:   "$set dotted path and $unset",
+			doc:    bson.M{"old": "x"},
+			update: bson.M{"$set": bson.M{"profile.name": "Ana"}, "$unset": bson.M{"old": ""}},
+			want:   bson.M{"profile": bson.M{"name": "Ana"}},
+		},
+		{
+			name:   "$push",
+			doc:    bson.M{"tags": bson.A{"a"}},
+			update: bson.M{"$push": bson.M{"tags": "b"}},
+			want:   bson.M{"tags": bson.A{"a", "b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := primitive.NewObjectID()
+			tt.doc["_id"] = id
+			coll := seed(t, tt.doc)
+
+			result, err := coll.UpdateOne(context.Background(), bson.M{"_id": id}, tt.update)
+			if err != nil {
+				t.Fatalf("update: %v", err)
+			}
+			if result.MatchedCount != 1 || result.ModifiedCount != 1 {
+				t.Fatalf("matched %d, modified %d, want 1 and 1", result.MatchedCount, result.ModifiedCount)
+			}
+
+			var got bson.M
+			if err := coll.FindOne(context.Background(), bson.M{"_id": id}, &got); err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			delete(got, "_id")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryFindOneAndUpdateUpsert(t *testing.T) {
+	coll := seed(t)
+	ctx := context.Background()
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempt struct {
+		Key      string `bson:"key"`
+		Failures int    `bson:"failures"`
+	}
+	for want := 1; want <= 2; want++ {
+		err := coll.FindOneAndUpdate(ctx, bson.M{"key": "ip:1"}, bson.M{"$inc": bson.M{"failures": 1}}, &attempt, opts)
+		if err != nil {
+			t.Fatalf("find one and update: %v", err)
+		}
+		if attempt.Key != "ip:1" || attempt.Failures != want {
+			t.Fatalf("got %+v, want key ip:1 with %d failures", attempt, want)
+		}
+	}
+
+	// Tanpa upsert dan tanpa dokumen yang cocok hasilnya ErrNoDocuments
+	err := coll.FindOneAndUpdate(ctx, bson.M{"key": "ip:2"}, bson.M{"$inc": bson.M{"failures": 1}}, &attempt)
+	if err != mongo.ErrNoDocuments {
+		t.Fatalf("got %v, want mongo.ErrNoDocuments", err)
+	}
+}
+
+func TestMemoryUniqueIndex(t *testing.T) {
+	ctx := context.Background()
+	coll := seed(t)
+	err := coll.CreateIndexes(ctx, []mongo.IndexModel{{
+		Keys: bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetCollation(&options.Collation{Locale: "en", Strength: 2}).
+			SetPartialFilterExpression(bson.M{"email": bson.M{"$gt": ""}}),
+	}})
+	if err != nil {
+		t.Fatalf("create indexes: %v", err)
+	}
+
+	if _, err := coll.InsertOne(ctx, bson.M{"email": "ana@example.com"}); err != nil {
+		t.Fatalf("first insert: %v", err)
+	}
+	if _, err := coll.InsertOne(ctx, bson.M{"email": "ANA@example.com"}); !mongo.IsDuplicateKeyError(err) {
+		t.Fatalf("case-insensitive duplicate: got %v, want a duplicate key error", err)
+	}
+
+	// Dokumen di luar partial filter tidak ikut dicek
+	for i := 0; i < 2; i++ {
+		if _, err := coll.InsertOne(ctx, bson.M{"name": "no email"}); err != nil {
+			t.Fatalf("insert without email: %v", err)
+		}
+	}
+
+	// Update yang membuat duplikat juga ditolak
+	id, err := coll.InsertOne(ctx, bson.M{"email": "budi@example.com"})
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	_, err = coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"email": "Ana@Example.com"}})
+	if !mongo.IsDuplicateKeyError(err) {
+		t.Fatalf("update to a duplicate: got %v, want a duplicate key error", err)
+	}
+}
@@ -3,7 +3,7 @@ package atdb
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"strconv"
 	"strings"
@@ -130,21 +130,27 @@ func GetCountDoc(db *mongo.Database, collection string, filter bson.M) (count in
 }
 
 func GetOneDoc[T any](db *mongo.Database, collection string, filter bson.M) (doc T, err error) {
+	ctx := context.Background()
 	// Temukan dokumen dengan filter yang diberikan
-	result := db.Collection(collection).FindOne(context.Background(), filter)
+	result := db.Collection(collection).FindOne(ctx, filter)
 
-	// Log dokumen sebelum melakukan decode untuk memeriksa data
-	rawDoc, err := result.DecodeBytes()
+	rawDoc, err := result.Raw()
 	if err != nil {
-		log.Printf("Error fetching presensi document: %v\n", err)
+		slog.Debug("atdb: document not fetched", "collection", collection, "error", err)
 		return
 	}
-	log.Printf("Raw document before decoding: %s\n", rawDoc.String())
+	// Isi dokumen hanya dicatat di level debug, dengan field rahasia disamarkan
+	if slog.Default().Enabled(ctx, slog.LevelDebug) {
+		var logged bson.M
+		if bson.Unmarshal(rawDoc, &logged) == nil {
+			slog.Debug("atdb: document fetched", "collection", collection, "document", Redact(logged))
+		}
+	}
 
 	// Decode dokumen ke struct yang diminta
 	err = result.Decode(&doc)
 	if err != nil {
-		log.Printf("Error decoding presensi document: %v\n", err)
+		slog.Debug("atdb: document not decoded", "collection", collection, "error", err)
 		return
 	}
 
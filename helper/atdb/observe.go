@@ -0,0 +1,96 @@
+package atdb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Observer menerima durasi dan hasil setiap operasi collection, mis. untuk metrik
+type Observer func(collection, operation string, duration time.Duration, err error)
+
+// Observe membungkus db sehingga setiap operasi collection dilaporkan ke observer
+func Observe(db Database, observer Observer) Database {
+	return &observedDatabase{Database: db, observer: observer}
+}
+
+type observedDatabase struct {
+	Database
+	observer Observer
+}
+
+func (d *observedDatabase) Collection(name string) Collection {
+	return &observedCollection{Collection: d.Database.Collection(name), observer: d.observer}
+}
+
+type observedCollection struct {
+	Collection
+	observer Observer
+}
+
+// observe dipanggil lewat defer; err berupa pointer agar yang dilaporkan adalah hasil akhirnya
+func (c *observedCollection) observe(operation string, start time.Time, err *error) {
+	c.observer(c.Name(), operation, time.Since(start), *err)
+}
+
+func (c *observedCollection) FindOne(ctx context.Context, filter, result interface{}, opts ...*options.FindOneOptions) (err error) {
+	defer c.observe("find_one", time.Now(), &err)
+	err = c.Collection.FindOne(ctx, filter, result, opts...)
+	return
+}
+
+func (c *observedCollection) Find(ctx context.Context, filter, results interface{}, opts ...*options.FindOptions) (err error) {
+	defer c.observe("find", time.Now(), &err)
+	err = c.Collection.Find(ctx, filter, results, opts...)
+	return
+}
+
+func (c *observedCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (count int64, err error) {
+	defer c.observe("count", time.Now(), &err)
+	count, err = c.Collection.CountDocuments(ctx, filter, opts...)
+	return
+}
+
+func (c *observedCollection) InsertOne(ctx context.Context, document interface{}) (id interface{}, err error) {
+	defer c.observe("insert_one", time.Now(), &err)
+	id, err = c.Collection.InsertOne(ctx, document)
+	return
+}
+
+func (c *observedCollection) UpdateOne(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (result *mongo.UpdateResult, err error) {
+	defer c.observe("update_one", time.Now(), &err)
+	result, err = c.Collection.UpdateOne(ctx, filter, update, opts...)
+	return
+}
+
+func (c *observedCollection) UpdateMany(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (result *mongo.UpdateResult, err error) {
+	defer c.observe("update_many", time.Now(), &err)
+	result, err = c.Collection.UpdateMany(ctx, filter, update, opts...)
+	return
+}
+
+func (c *observedCollection) FindOneAndUpdate(ctx context.Context, filter, update, result interface{}, opts ...*options.FindOneAndUpdateOptions) (err error) {
+	defer c.observe("find_one_and_update", time.Now(), &err)
+	err = c.Collection.FindOneAndUpdate(ctx, filter, update, result, opts...)
+	return
+}
+
+func (c *observedCollection) DeleteOne(ctx context.Context, filter interface{}) (result *mongo.DeleteResult, err error) {
+	defer c.observe("delete_one", time.Now(), &err)
+	result, err = c.Collection.DeleteOne(ctx, filter)
+	return
+}
+
+func (c *observedCollection) DeleteMany(ctx context.Context, filter interface{}) (result *mongo.DeleteResult, err error) {
+	defer c.observe("delete_many", time.Now(), &err)
+	result, err = c.Collection.DeleteMany(ctx, filter)
+	return
+}
+
+func (c *observedCollection) CreateIndexes(ctx context.Context, models []mongo.IndexModel) (err error) {
+	defer c.observe("create_indexes", time.Now(), &err)
+	err = c.Collection.CreateIndexes(ctx, models)
+	return
+}
@@ -0,0 +1,47 @@
+package atdb
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// redactedFields adalah potongan nama field yang nilainya tidak boleh muncul di log
+var redactedFields = []string{"password", "hash", "secret", "token", "recovery_code"}
+
+// Redact menyalin dokumen untuk keperluan log dengan nilai field rahasia diganti "[REDACTED]"
+func Redact(doc bson.M) bson.M {
+	out := make(bson.M, len(doc))
+	for key, value := range doc {
+		if isSecretField(key) {
+			out[key] = "[REDACTED]"
+			continue
+		}
+		switch v := value.(type) {
+		case bson.M:
+			out[key] = Redact(v)
+		case bson.A:
+			items := make(bson.A, len(v))
+			for i, item := range v {
+				if sub, ok := item.(bson.M); ok {
+					item = Redact(sub)
+				}
+				items[i] = item
+			}
+			out[key] = items
+		default:
+			out[key] = value
+		}
+	}
+	return out
+}
+
+func isSecretField(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range redactedFields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package atdb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Database adalah sumber koleksi dokumen yang dipakai repository. MongoDatabase
+// membungkus *mongo.Database; implementasi lain cukup memenuhi interface ini.
+type Database interface {
+	Collection(name string) Collection
+	Ping(ctx context.Context) error
+	Disconnect(ctx context.Context) error
+}
+
+// Collection adalah operasi dokumen atas satu koleksi. Filter dan update memakai
+// bentuk query MongoDB; FindOne mengembalikan mongo.ErrNoDocuments jika tidak ada hasil.
+type Collection interface {
+	Name() string
+	FindOne(ctx context.Context, filter, result interface{}, opts ...*options.FindOneOptions) error
+	Find(ctx context.Context, filter, results interface{}, opts ...*options.FindOptions) error
+	CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error)
+	InsertOne(ctx context.Context, document interface{}) (interface{}, error)
+	UpdateOne(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	UpdateMany(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	// FindOneAndUpdate mengubah satu dokumen secara atomik dan men-decode dokumen sebelum
+	// atau sesudah perubahan (sesuai ReturnDocument) ke result
+	FindOneAndUpdate(ctx context.Context, filter, update, result interface{}, opts ...*options.FindOneAndUpdateOptions) error
+	DeleteOne(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error)
+	DeleteMany(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error)
+	CreateIndexes(ctx context.Context, models []mongo.IndexModel) error
+}
+
+// MongoDatabase adalah Database yang disimpan di MongoDB
+type MongoDatabase struct {
+	db *mongo.Database
+}
+
+// NewMongoDatabase membungkus koneksi MongoDB sebagai Database
+func NewMongoDatabase(db *mongo.Database) *MongoDatabase {
+	return &MongoDatabase{db: db}
+}
+
+func (m *MongoDatabase) Collection(name string) Collection {
+	return mongoCollection{m.db.Collection(name)}
+}
+
+func (m *MongoDatabase) Ping(ctx context.Context) error {
+	return m.db.Client().Ping(ctx, nil)
+}
+
+func (m *MongoDatabase) Disconnect(ctx context.Context) error {
+	return m.db.Client().Disconnect(ctx)
+}
+
+type mongoCollection struct {
+	coll *mongo.Collection
+}
+
+func (c mongoCollection) Name() string {
+	return c.coll.Name()
+}
+
+func (c mongoCollection) FindOne(ctx context.Context, filter, result interface{}, opts ...*options.FindOneOptions) error {
+	return c.coll.FindOne(ctx, filter, opts...).Decode(result)
+}
+
+func (c mongoCollection) Find(ctx context.Context, filter, results interface{}, opts ...*options.FindOptions) error {
+	cursor, err := c.coll.Find(ctx, filter, opts...)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, results)
+}
+
+func (c mongoCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return c.coll.CountDocuments(ctx, filter, opts...)
+}
+
+func (c mongoCollection) InsertOne(ctx context.Context, document interface{}) (interface{}, error) {
+	result, err := c.coll.InsertOne(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+	return result.InsertedID, nil
+}
+
+func (c mongoCollection) UpdateOne(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return c.coll.UpdateOne(ctx, filter, update, opts...)
+}
+
+func (c mongoCollection) UpdateMany(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return c.coll.UpdateMany(ctx, filter, update, opts...)
+}
+
+func (c mongoCollection) FindOneAndUpdate(ctx context.Context, filter, update, result interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	return c.coll.FindOneAndUpdate(ctx, filter, update, opts...).Decode(result)
+}
+
+func (c mongoCollection) DeleteOne(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error) {
+	return c.coll.DeleteOne(ctx, filter)
+}
+
+func (c mongoCollection) DeleteMany(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error) {
+	return c.coll.DeleteMany(ctx, filter)
+}
+
+func (c mongoCollection) CreateIndexes(ctx context.Context, models []mongo.IndexModel) error {
+	_, err := c.coll.Indexes().CreateMany(ctx, models)
+	return err
+}
@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"plastiqu_co/helper/logger"
+	"plastiqu_co/middleware"
+	"plastiqu_co/model"
+	"plastiqu_co/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// redacted menggantikan nilai field sensitif di dalam diff
+const redacted = "[redacted]"
+
+// Event menjelaskan satu aksi yang dicatat. Before dan After boleh berupa struct
+// model, bson.M, atau nil untuk aksi create (Before nil) dan delete (After nil).
+type Event struct {
+	Action   string
+	Entity   string
+	EntityID string
+	Before   interface{}
+	After    interface{}
+}
+
+// sensitiveFields dicatat perubahannya tanpa menyimpan nilainya
+var sensitiveFields = map[string]bool{
+	"password":   true,
+	"token_hash": true,
+	"two_factor": true,
+}
+
+// ignoredFields tidak dimasukkan ke dalam diff
+var ignoredFields = map[string]bool{
+	"_id":        true,
+	"updated_at": true,
+}
+
+// Recorder menulis jejak audit ke koleksi append-only audit_log
+type Recorder struct {
+	entries repository.AuditLogRepository
+}
+
+// NewRecorder membuat Recorder yang menyimpan entri ke repository yang diberikan
+func NewRecorder(entries repository.AuditLogRepository) *Recorder {
+	return &Recorder{entries: entries}
+}
+
+// Record mencatat aksi yang dilakukan oleh principal pada request r. Dipanggil setelah
+// aksi berhasil; kegagalan menulis audit hanya dicatat ke log agar tidak membatalkan
+// aksi yang sudah terjadi.
+func (rec *Recorder) Record(r *http.Request, event Event) {
+	changes, err := diff(event.Before, event.After)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("audit: failed to diff", "action", event.Action, "entity_id", event.EntityID, "error", err)
+	}
+
+	entry := model.AuditLog{
+		Action:   event.Action,
+		Entity:   event.Entity,
+		EntityID: event.EntityID,
+		Changes:  changes,
+		Request: model.AuditRequest{
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			IP:        middleware.ClientIP(r),
+			UserAgent: r.UserAgent(),
+		},
+		CreatedAt: time.Now(),
+	}
+	if principal, ok := middleware.PrincipalFromContext(r.Context()); ok {
+		if !principal.UserID.IsZero() {
+			entry.ActorID = &principal.UserID
+			entry.ActorRole = principal.Role
+		}
+		if !principal.APIKeyID.IsZero() {
+			entry.APIKeyID = &principal.APIKeyID
+		}
+	}
+
+	// Context terpisah agar entri tetap tertulis walaupun klien sudah memutus koneksi
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rec.entries.Insert(ctx, entry); err != nil {
+		logger.FromContext(r.Context()).Error("audit: failed to record", "action", event.Action, "entity_id", event.EntityID, "error", err)
+	}
+}
+
+// diff membandingkan dua dokumen dan mengembalikan field yang berubah, terurut berdasarkan nama
+func diff(before, after interface{}) ([]model.AuditChange, error) {
+	old, err := toDocument(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := toDocument(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]bool{}
+	for field := range old {
+		fields[field] = true
+	}
+	for field := range updated {
+		fields[field] = true
+	}
+
+	var changes []model.AuditChange
+	for field := range fields {
+		if ignoredFields[field] {
+			continue
+		}
+		oldValue, newValue := old[field], updated[field]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if sensitiveFields[field] {
+			oldValue, newValue = redact(oldValue), redact(newValue)
+		}
+		changes = append(changes, model.AuditChange{Field: field, Before: oldValue, After: newValue})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// toDocument mengubah struct atau map menjadi bson.M sehingga keduanya dapat dibandingkan per field
+func toDocument(v interface{}) (bson.M, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return bson.M{}, nil
+	}
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	err = bson.Unmarshal(data, &doc)
+	return doc, err
+}
+
+func redact(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return redacted
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// New membuat logger terstruktur. Format JSON dipakai di production agar mudah diolah
+// agregator log, format teks untuk pengembangan lokal.
+func New(w io.Writer, level slog.Level, json bool) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if json {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+)
+
+// WithContext menyimpan logger (biasanya sudah berisi request_id) ke dalam context
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext mengambil logger milik request, atau logger default jika tidak ada
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// WithRequestID menyimpan ID request ke dalam context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID mengambil ID request, atau string kosong di luar request HTTP
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
@@ -0,0 +1,15 @@
+package mailer
+
+import "context"
+
+// Message adalah email teks sederhana yang akan dikirim
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender mengirim email. Implementasi tersedia untuk SMTP dan outbox berbasis file.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// OutboxSender menulis setiap email sebagai file .eml ke direktori lokal,
+// berguna untuk pengembangan dan pengujian tanpa server SMTP.
+type OutboxSender struct {
+	Dir  string
+	From string
+}
+
+// Send menyimpan pesan ke Dir dengan nama file berdasarkan waktu dan penerima
+func (s OutboxSender) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To)
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), recipient)
+	return os.WriteFile(filepath.Join(s.Dir, name), buildMessage(s.From, msg), 0o644)
+}
@@ -0,0 +1,148 @@
+package metric
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+)
+
+const (
+	// AccessTokenDuration adalah masa berlaku access token hasil login
+	AccessTokenDuration = 15 * time.Minute
+	// RefreshTokenDuration adalah masa berlaku refresh token
+	RefreshTokenDuration = 30 * 24 * time.Hour
+	// ChallengeTokenDuration adalah masa berlaku token langkah kedua login (2FA)
+	ChallengeTokenDuration = 5 * time.Minute
+)
+
+// Purpose membatasi kegunaan token selain access token biasa
+const (
+	PurposeTwoFactorChallenge  = "2fa_challenge"  // Password benar, menunggu kode TOTP
+	PurposeTwoFactorEnrollment = "2fa_enrollment" // Role mewajibkan 2FA, hanya boleh mendaftarkan TOTP
+)
+
+var (
+	ErrInvalidToken = errors.New("token is invalid")
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// Payload berisi klaim yang dibawa oleh access token
+type Payload struct {
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	SessionID string    `json:"session_id,omitempty"` // Sesi login asal token, lihat model.Session
+	Purpose   string    `json:"purpose,omitempty"`    // Kosong untuk access token biasa
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// tokenFooter disisipkan tanpa enkripsi pada token untuk memilih kunci dari key ring
+type tokenFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// EncodeToken membuat PASETO v4.local yang berisi user ID, role, ID sesi dan waktu kedaluwarsa
+func EncodeToken(userID, role, sessionID string, duration time.Duration) (token string, payload Payload, err error) {
+	return encodeToken(Payload{UserID: userID, Role: role, SessionID: sessionID}, duration)
+}
+
+// EncodePurposeToken membuat token dengan klaim purpose, mis. PurposeTwoFactorChallenge.
+// Token dengan purpose tidak diterima sebagai access token biasa.
+func EncodePurposeToken(userID, role, purpose string, duration time.Duration) (token string, payload Payload, err error) {
+	return encodeToken(Payload{UserID: userID, Role: role, Purpose: purpose}, duration)
+}
+
+func encodeToken(payload Payload, duration time.Duration) (string, Payload, error) {
+	ring, err := currentKeyRing()
+	if err != nil {
+		return "", Payload{}, err
+	}
+	keyID := ring.ActiveKeyID()
+	key, _ := ring.key(keyID)
+	footer, err := json.Marshal(tokenFooter{KeyID: keyID})
+	if err != nil {
+		return "", Payload{}, err
+	}
+
+	now := time.Now()
+	payload.IssuedAt = now
+	payload.ExpiresAt = now.Add(duration)
+
+	t := paseto.NewToken()
+	t.SetSubject(payload.UserID)
+	t.SetString("role", payload.Role)
+	if payload.SessionID != "" {
+		t.SetString("sid", payload.SessionID)
+	}
+	if payload.Purpose != "" {
+		t.SetString("purpose", payload.Purpose)
+	}
+	t.SetIssuedAt(payload.IssuedAt)
+	t.SetNotBefore(payload.IssuedAt)
+	t.SetExpiration(payload.ExpiresAt)
+	t.SetFooter(footer)
+
+	return t.V4Encrypt(key, nil), payload, nil
+}
+
+// DecodeToken mendekripsi dan memverifikasi token, lalu mengembalikan payload-nya.
+// Token yang sudah lewat masa berlakunya menghasilkan ErrExpiredToken.
+func DecodeToken(token string) (payload Payload, err error) {
+	ring, err := currentKeyRing()
+	if err != nil {
+		return
+	}
+
+	parser := paseto.NewParserWithoutExpiryCheck()
+
+	// Pilih kunci berdasarkan key ID pada footer sebelum mendekripsi
+	rawFooter, err := parser.UnsafeParseFooter(paseto.V4Local, token)
+	if err != nil {
+		err = ErrInvalidToken
+		return
+	}
+	var footer tokenFooter
+	if err = json.Unmarshal(rawFooter, &footer); err != nil {
+		err = ErrInvalidToken
+		return
+	}
+	key, ok := ring.key(footer.KeyID)
+	if !ok {
+		err = ErrInvalidToken
+		return
+	}
+
+	t, err := parser.ParseV4Local(key, token, nil)
+	if err != nil {
+		err = ErrInvalidToken
+		return
+	}
+
+	if payload.UserID, err = t.GetSubject(); err != nil {
+		err = ErrInvalidToken
+		return
+	}
+	if payload.Role, err = t.GetString("role"); err != nil {
+		err = ErrInvalidToken
+		return
+	}
+	// Klaim sid dan purpose opsional
+	payload.SessionID, _ = t.GetString("sid")
+	payload.Purpose, _ = t.GetString("purpose")
+	if payload.IssuedAt, err = t.GetIssuedAt(); err != nil {
+		err = ErrInvalidToken
+		return
+	}
+	if payload.ExpiresAt, err = t.GetExpiration(); err != nil {
+		err = ErrInvalidToken
+		return
+	}
+
+	if time.Now().After(payload.ExpiresAt) {
+		err = ErrExpiredToken
+		return
+	}
+	return
+}
@@ -2,16 +2,192 @@ package metric
 
 import (
 	"crypto/rand"
-	"log"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
 )
 
-func generateSymmetricKey() []byte {
-	key := make([]byte, 32) // 32 byte panjang
-	_, err := rand.Read(key)
+// KeyRing menyimpan kunci simetris PASETO berdasarkan key ID. Token baru selalu
+// dienkripsi dengan kunci aktif, sedangkan kunci lain tetap diterima untuk
+// mendekripsi token lama. Rotasi dilakukan dengan menambah kunci baru, menjadikannya
+// aktif, lalu menghapus kunci lama setelah semua token yang memakainya kedaluwarsa.
+type KeyRing struct {
+	active string
+	keys   map[string]paseto.V4SymmetricKey
+}
+
+// keyRingFile adalah format file yang dibaca dari PASETO_KEYS_FILE
+type keyRingFile struct {
+	Active string            `json:"active"`
+	Keys   map[string]string `json:"keys"` // key ID -> kunci 32 byte dalam hex
+}
+
+// NewKeyRing membuat key ring dari pasangan key ID dan kunci hex. active harus ada di keys.
+func NewKeyRing(active string, keys map[string]string) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("key ring must contain at least one key")
+	}
+	ring := &KeyRing{active: active, keys: make(map[string]paseto.V4SymmetricKey, len(keys))}
+	for id, hexKey := range keys {
+		if id == "" || strings.ContainsAny(id, ":,") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		key, err := paseto.V4SymmetricKeyFromHex(strings.TrimSpace(hexKey))
+		if err != nil {
+			return nil, fmt.Errorf("key %q: must be 32 bytes hex encoded", id)
+		}
+		ring.keys[id] = key
+	}
+	if _, ok := ring.keys[active]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the key ring", active)
+	}
+	return ring, nil
+}
+
+// ActiveKeyID mengembalikan ID kunci yang dipakai untuk token baru
+func (k *KeyRing) ActiveKeyID() string {
+	return k.active
+}
+
+// KeyIDs mengembalikan semua ID kunci yang diterima, terurut
+func (k *KeyRing) KeyIDs() []string {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (k *KeyRing) key(id string) (paseto.V4SymmetricKey, bool) {
+	key, ok := k.keys[id]
+	return key, ok
+}
+
+// LoadKeyRingFromEnv memuat key ring langsung dari environment variable
+// PASETO_KEYS_FILE, PASETO_KEYS dan PASETO_ACTIVE_KEY
+func LoadKeyRingFromEnv() (*KeyRing, error) {
+	return LoadKeyRing(os.Getenv("PASETO_KEYS_FILE"), os.Getenv("PASETO_KEYS"), os.Getenv("PASETO_ACTIVE_KEY"))
+}
+
+// LoadKeyRing membuat key ring dari konfigurasi (lihat config.Config):
+//   - path: file JSON {"active": "<id>", "keys": {"<id>": "<hex>"}} (PASETO_KEYS_FILE)
+//   - list: daftar "<id>:<hex>" dipisah koma (PASETO_KEYS), dengan active sebagai
+//     kunci aktif (PASETO_ACTIVE_KEY, default: kunci terakhir pada daftar)
+//
+// Jika keduanya kosong, kunci sementara dibangkitkan sehingga token tidak bertahan
+// setelah restart; hanya cocok untuk pengembangan lokal.
+func LoadKeyRing(path, list, active string) (*KeyRing, error) {
+	if path != "" {
+		return LoadKeyRingFile(path)
+	}
+	if list != "" {
+		return ParseKeyRing(list, active)
+	}
+
+	slog.Warn("PASETO_KEYS is not set, using an ephemeral token key; tokens will not survive a restart")
+	id, hexKey, err := GenerateKey()
 	if err != nil {
-		log.Fatal("Failed to generate random key:", err)
+		return nil, err
 	}
-	return key
+	return NewKeyRing(id, map[string]string{id: hexKey})
 }
 
-var symmetricKey = generateSymmetricKey() // Hasilkan kunci simetris dengan panjang 32 byte
+// LoadKeyRingFile membaca key ring dari file JSON
+func LoadKeyRingFile(path string) (*KeyRing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file keyRingFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return NewKeyRing(file.Active, file.Keys)
+}
+
+// ParseKeyRing membaca key ring dari format "<id>:<hex>,<id>:<hex>"
+func ParseKeyRing(list, active string) (*KeyRing, error) {
+	keys := map[string]string{}
+	last := ""
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, hexKey, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid key entry %q, expected <id>:<hex>", entry)
+		}
+		keys[id] = hexKey
+		last = id
+	}
+	if active == "" {
+		active = last
+	}
+	return NewKeyRing(active, keys)
+}
+
+// GenerateKey membangkitkan kunci simetris 32 byte baru beserta key ID berbasis tanggal
+func GenerateKey() (id, hexKey string, err error) {
+	key := make([]byte, 32) // 32 byte panjang
+	if _, err = rand.Read(key); err != nil {
+		return
+	}
+	suffix := make([]byte, 3)
+	if _, err = rand.Read(suffix); err != nil {
+		return
+	}
+	id = "k" + time.Now().Format("20060102") + "-" + hex.EncodeToString(suffix)
+	hexKey = hex.EncodeToString(key)
+	return
+}
+
+var (
+	keyRingMu   sync.RWMutex
+	keyRing     *KeyRing
+	keyRingOnce sync.Once
+)
+
+// SetKeyRing mengganti key ring yang dipakai EncodeToken dan DecodeToken
+func SetKeyRing(ring *KeyRing) {
+	keyRingMu.Lock()
+	keyRing = ring
+	keyRingMu.Unlock()
+}
+
+// currentKeyRing mengembalikan key ring aktif, memuatnya dari environment jika belum di-set
+func currentKeyRing() (*KeyRing, error) {
+	var err error
+	keyRingOnce.Do(func() {
+		keyRingMu.RLock()
+		loaded := keyRing != nil
+		keyRingMu.RUnlock()
+		if loaded {
+			return
+		}
+		var ring *KeyRing
+		if ring, err = LoadKeyRingFromEnv(); err == nil {
+			SetKeyRing(ring)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keyRingMu.RLock()
+	defer keyRingMu.RUnlock()
+	if keyRing == nil {
+		return nil, errors.New("token key ring is not configured")
+	}
+	return keyRing, nil
+}
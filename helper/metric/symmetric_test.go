@@ -0,0 +1,158 @@
+package metric
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKey membangkitkan satu kunci baru dalam format "<id>:<hex>"
+func testKey(t *testing.T, id string) (entry, hexKey string) {
+	t.Helper()
+	_, hexKey, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return id + ":" + hexKey, hexKey
+}
+
+// useKeyRing memasang ring untuk satu test dan mengembalikan key ring sebelumnya setelahnya
+func useKeyRing(t *testing.T, ring *KeyRing) {
+	t.Helper()
+	keyRingMu.RLock()
+	previous := keyRing
+	keyRingMu.RUnlock()
+	SetKeyRing(ring)
+	t.Cleanup(func() { SetKeyRing(previous) })
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	oldEntry, _ := testKey(t, "k1")
+	newEntry, _ := testKey(t, "k2")
+
+	before, err := ParseKeyRing(oldEntry, "")
+	if err != nil {
+		t.Fatalf("ParseKeyRing: %v", err)
+	}
+	useKeyRing(t, before)
+	oldToken, _, err := EncodeToken("user-1", "admin", "session-1", time.Hour)
+	if err != nil {
+		t.Fatalf("EncodeToken: %v", err)
+	}
+
+	// Kunci baru aktif, kunci lama masih diterima untuk token yang sudah terbit
+	during, err := ParseKeyRing(oldEntry+","+newEntry, "k2")
+	if err != nil {
+		t.Fatalf("ParseKeyRing: %v", err)
+	}
+	SetKeyRing(during)
+	payload, err := DecodeToken(oldToken)
+	if err != nil {
+		t.Fatalf("decode token signed with the previous key: %v", err)
+	}
+	if payload.UserID != "user-1" || payload.Role != "admin" || payload.SessionID != "session-1" {
+		t.Errorf("payload = %+v", payload)
+	}
+	newToken, _, err := EncodeToken("user-1", "admin", "session-1", time.Hour)
+	if err != nil {
+		t.Fatalf("EncodeToken: %v", err)
+	}
+
+	// Setelah kunci lama dihapus, hanya token dari kunci baru yang berlaku
+	after, err := ParseKeyRing(newEntry, "")
+	if err != nil {
+		t.Fatalf("ParseKeyRing: %v", err)
+	}
+	SetKeyRing(after)
+	if _, err := DecodeToken(oldToken); err != ErrInvalidToken {
+		t.Errorf("decode token signed with a removed key: got %v, want ErrInvalidToken", err)
+	}
+	if _, err := DecodeToken(newToken); err != nil {
+		t.Errorf("decode token signed with the active key: %v", err)
+	}
+}
+
+func TestDecodeTokenRejectsExpiredAndTampered(t *testing.T) {
+	entry, _ := testKey(t, "k1")
+	ring, err := ParseKeyRing(entry, "")
+	if err != nil {
+		t.Fatalf("ParseKeyRing: %v", err)
+	}
+	useKeyRing(t, ring)
+
+	expired, _, err := EncodeToken("user-1", "user", "", -time.Minute)
+	if err != nil {
+		t.Fatalf("EncodeToken: %v", err)
+	}
+	if _, err := DecodeToken(expired); err != ErrExpiredToken {
+		t.Errorf("expired token: got %v, want ErrExpiredToken", err)
+	}
+
+	token, _, err := EncodeToken("user-1", "user", "", time.Hour)
+	if err != nil {
+		t.Fatalf("EncodeToken: %v", err)
+	}
+	parts := strings.Split(token, ".")
+	body := []byte(parts[2])
+	body[10] ^= 1
+	parts[2] = string(body)
+	if _, err := DecodeToken(strings.Join(parts, ".")); err != ErrInvalidToken {
+		t.Errorf("tampered token: got %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestParseKeyRing(t *testing.T) {
+	first, _ := testKey(t, "k1")
+	second, _ := testKey(t, "k2")
+
+	tests := []struct {
+		name       string
+		list       string
+		active     string
+		wantActive string
+		wantErr    bool
+	}{
+		{"last key is active by default", first + "," + second, "", "k2", false},
+		{"explicit active key", first + ", " + second, "k1", "k1", false},
+		{"unknown active key", first, "k9", "", true},
+		{"entry without id", strings.TrimPrefix(first, "k1:"), "", "", true},
+		{"key that is not 32 bytes", "k1:abcd", "", "", true},
+		{"empty list", " , ", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := ParseKeyRing(tt.list, tt.active)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got key ring %v, want an error", ring.KeyIDs())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseKeyRing: %v", err)
+			}
+			if ring.ActiveKeyID() != tt.wantActive {
+				t.Errorf("active key = %s, want %s", ring.ActiveKeyID(), tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestLoadKeyRingFile(t *testing.T) {
+	_, hexKey := testKey(t, "k1")
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, []byte(`{"active":"k1","keys":{"k1":"`+hexKey+`"}}`), 0o600); err != nil {
+		t.Fatalf("write key file: %v", err)
+	}
+
+	// File didahulukan dari daftar kunci
+	ring, err := LoadKeyRing(path, "ignored:00", "")
+	if err != nil {
+		t.Fatalf("LoadKeyRing: %v", err)
+	}
+	if ring.ActiveKeyID() != "k1" || len(ring.KeyIDs()) != 1 {
+		t.Errorf("key ring = %s %v, want k1 only", ring.ActiveKeyID(), ring.KeyIDs())
+	}
+}
@@ -0,0 +1,348 @@
+// Package openapi membangun dokumen OpenAPI 3 dari router dan tipe Go yang dipakai handler.
+// Daftar path, method dan skema keamanan dibaca langsung dari router, sedangkan skema
+// request dan respon diturunkan dari struct (termasuk tag `validate`), sehingga dokumen
+// tidak bisa tertinggal dari kode.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"plastiqu_co/helper/response"
+
+	"github.com/gorilla/mux"
+)
+
+// Skema keamanan yang dikenal dokumen
+const (
+	BearerAuth = "bearerAuth" // Authorization: Bearer <token PASETO>
+	APIKeyAuth = "apiKeyAuth" // Authorization: ApiKey <key>
+)
+
+// Info mengisi objek info dokumen
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Param menjelaskan query parameter sebuah operasi. Path parameter dibaca dari template route.
+type Param struct {
+	Name        string
+	Description string
+	Example     interface{} // Contoh nilai; tipenya menentukan tipe skema (default string)
+	Required    bool
+}
+
+// Operation adalah dokumentasi satu route. Request dan Response berisi contoh nilai
+// (mis. model.Category{} atau Object{...}) yang skemanya diturunkan lewat refleksi.
+type Operation struct {
+	Tag         string
+	Summary     string
+	Description string
+	Query       []Param
+	Request     interface{}
+	Partial     bool // Body boleh berisi sebagian field, seperti pada validate.Patch
+	Response    interface{}
+	Status      int   // Status sukses, default 200
+	Errors      []int // Status error tambahan di luar yang diturunkan otomatis, mis. 409
+}
+
+// Operations memetakan "METHOD /path/template" ke dokumentasinya
+type Operations map[string]Operation
+
+// Secured menandai handler yang dibungkus middleware autentikasi agar skema keamanan
+// dan permission-nya ikut tercantum di dokumen
+type Secured struct {
+	http.Handler
+	Schemes    []string
+	Permission string
+}
+
+// Secure membungkus handler yang sudah diautentikasi dengan keterangan keamanannya
+func Secure(handler http.Handler, permission string, schemes ...string) http.Handler {
+	return Secured{Handler: handler, Schemes: schemes, Permission: permission}
+}
+
+// Document adalah dokumen OpenAPI yang dibangun sekali dari router lalu disajikan sebagai JSON
+type Document struct {
+	info       Info
+	operations Operations
+	body       []byte
+}
+
+// New membuat Document kosong; panggil Build setelah semua route didaftarkan
+func New(info Info, operations Operations) *Document {
+	return &Document{info: info, operations: operations}
+}
+
+type document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]operation `json:"paths"`
+	Components components                      `json:"components"`
+	Tags       []tag                           `json:"tags,omitempty"`
+}
+
+type components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	Responses       map[string]responseObject `json:"responses"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes"`
+}
+
+type tag struct {
+	Name string `json:"name"`
+}
+
+type operation struct {
+	Tags        []string                  `json:"tags,omitempty"`
+	Summary     string                    `json:"summary,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	OperationID string                    `json:"operationId"`
+	Parameters  []parameter               `json:"parameters,omitempty"`
+	RequestBody *requestBody              `json:"requestBody,omitempty"`
+	Responses   map[string]responseObject `json:"responses"`
+	Security    []map[string][]string     `json:"security,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type responseObject struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type securityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// errorResponses adalah respon error bersama; semuanya memakai response.Envelope
+var errorResponses = map[int]string{
+	http.StatusBadRequest:          "The request is malformed or failed validation.",
+	http.StatusUnauthorized:        "Authentication is missing or invalid.",
+	http.StatusForbidden:           "The caller lacks the required permission.",
+	http.StatusNotFound:            "The resource does not exist.",
+	http.StatusConflict:            "The resource conflicts with an existing one.",
+	http.StatusTooManyRequests:     "Too many attempts; retry later.",
+	http.StatusInternalServerError: "An unexpected error occurred.",
+	http.StatusServiceUnavailable:  "A dependency such as the database is unavailable.",
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+
+// Build menelusuri semua route di router dan menyusun dokumen. Route tanpa dokumentasi
+// tetap dicantumkan dan dokumen tetap disajikan, tetapi route tersebut serta dokumentasi
+// tanpa route dikembalikan sebagai error agar ketidaksesuaian gagal di pengujian.
+func (d *Document) Build(router *mux.Router) error {
+	s := newSchemas()
+	errorSchema := s.of(response.Envelope{})
+	doc := document{
+		OpenAPI: "3.0.3",
+		Info:    d.info,
+		Paths:   map[string]map[string]operation{},
+		Components: components{
+			Schemas:   s.components,
+			Responses: map[string]responseObject{},
+			SecuritySchemes: map[string]securityScheme{
+				BearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "PASETO"},
+				APIKeyAuth: {Type: "apiKey", In: "header", Name: "Authorization", Description: `Format "ApiKey <key>".`},
+			},
+		},
+	}
+	for status, description := range errorResponses {
+		doc.Components.Responses[responseName(status)] = responseObject{
+			Description: description,
+			Content:     map[string]mediaType{"application/json": {Schema: errorSchema}},
+		}
+	}
+
+	documented := map[string]bool{}
+	tags := map[string]bool{}
+	var drift []string
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil // Route tanpa path, mis. subrouter berbasis host
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			key := method + " " + template
+			op, ok := d.operations[key]
+			if !ok {
+				drift = append(drift, "route "+key+" is not documented")
+			}
+			documented[key] = true
+			if op.Tag != "" {
+				tags[op.Tag] = true
+			}
+
+			path := pathParamPattern.ReplaceAllString(template, "{$1}")
+			if doc.Paths[path] == nil {
+				doc.Paths[path] = map[string]operation{}
+			}
+			doc.Paths[path][strings.ToLower(method)] = d.operation(s, method, template, op, route.GetHandler())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for key := range d.operations {
+		if !documented[key] {
+			drift = append(drift, "documented operation "+key+" has no route")
+		}
+	}
+	sort.Strings(drift)
+
+	for name := range tags {
+		doc.Tags = append(doc.Tags, tag{Name: name})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("openapi: encode document: %w", err)
+	}
+	d.body = body
+	if len(drift) > 0 {
+		return fmt.Errorf("openapi: document does not match the router: %s", strings.Join(drift, "; "))
+	}
+	return nil
+}
+
+func (d *Document) operation(s *schemas, method, template string, op Operation, handler http.Handler) operation {
+	result := operation{
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: operationID(method, template),
+		Responses:   map[string]responseObject{},
+	}
+	if op.Tag != "" {
+		result.Tags = []string{op.Tag}
+	}
+
+	for _, match := range pathParamPattern.FindAllStringSubmatch(template, -1) {
+		result.Parameters = append(result.Parameters, parameter{
+			Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	for _, param := range op.Query {
+		schema := &Schema{Type: "string"}
+		if param.Example != nil {
+			schema = s.of(param.Example)
+		}
+		result.Parameters = append(result.Parameters, parameter{
+			Name: param.Name, In: "query", Description: param.Description, Required: param.Required, Schema: schema,
+		})
+	}
+
+	if op.Request != nil {
+		schema := s.of(op.Request)
+		if op.Partial {
+			schema = partial(s, schema)
+		}
+		result.RequestBody = &requestBody{
+			Required: true,
+			Content:  map[string]mediaType{"application/json": {Schema: schema}},
+		}
+		result.Responses[strconv.Itoa(http.StatusBadRequest)] = errorRef(http.StatusBadRequest)
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := responseObject{Description: http.StatusText(status)}
+	if op.Response != nil {
+		success.Content = map[string]mediaType{"application/json": {Schema: s.of(op.Response)}}
+	}
+	result.Responses[strconv.Itoa(status)] = success
+
+	if secured, ok := handler.(Secured); ok {
+		for _, scheme := range secured.Schemes {
+			result.Security = append(result.Security, map[string][]string{scheme: {}})
+		}
+		result.Responses[strconv.Itoa(http.StatusUnauthorized)] = errorRef(http.StatusUnauthorized)
+		if secured.Permission != "" {
+			result.Responses[strconv.Itoa(http.StatusForbidden)] = errorRef(http.StatusForbidden)
+			result.Description = strings.TrimSpace(result.Description + "\n\nRequires permission `" + secured.Permission + "`.")
+		}
+	}
+	if strings.Contains(template, "{") {
+		result.Responses[strconv.Itoa(http.StatusNotFound)] = errorRef(http.StatusNotFound)
+	}
+	for _, status := range op.Errors {
+		result.Responses[strconv.Itoa(status)] = errorRef(status)
+	}
+	result.Responses[strconv.Itoa(http.StatusInternalServerError)] = errorRef(http.StatusInternalServerError)
+	return result
+}
+
+// partial menyalin skema body tanpa daftar field wajib di tingkat teratas, sesuai validate.Patch
+func partial(s *schemas, schema *Schema) *Schema {
+	if name := strings.TrimPrefix(schema.Ref, "#/components/schemas/"); name != schema.Ref {
+		schema = s.components[name]
+	}
+	clone := *schema
+	clone.Required = nil
+	return &clone
+}
+
+func errorRef(status int) responseObject {
+	return responseObject{Ref: "#/components/responses/" + responseName(status)}
+}
+
+// responseName mengubah status menjadi nama komponen, mis. 404 menjadi "NotFound"
+func responseName(status int) string {
+	return strings.ReplaceAll(http.StatusText(status), " ", "")
+}
+
+// operationID membentuk ID unik dari method dan path, mis. "get_products_id"
+func operationID(method, template string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(pathParamPattern.ReplaceAllString(template, "$1"), func(r rune) bool {
+		return r == '/' || r == '-' || r == '.'
+	}) {
+		id += "_" + part
+	}
+	return id
+}
+
+// ServeHTTP menyajikan dokumen sebagai JSON
+func (d *Document) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if d.body == nil {
+		response.WriteError(w, r, response.Unavailable("Documentation unavailable", "The API documentation has not been built."))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(d.body)
+}
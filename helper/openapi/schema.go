@@ -0,0 +1,295 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"plastiqu_co/helper/validate"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Schema adalah subset Schema Object OpenAPI 3.0 yang dipakai dokumen ini
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// Object menjelaskan respon berupa map JSON ad hoc, mis. {"message": ..., "banner": ...}.
+// Nilai setiap key adalah contoh bertipe Go yang skemanya diturunkan lewat refleksi.
+type Object map[string]interface{}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+	objectType   = reflect.TypeOf(Object{})
+	rawJSONType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// objectIDPattern adalah format hex ObjectID MongoDB
+const objectIDPattern = "^[0-9a-fA-F]{24}$"
+
+// schemas membangun komponen skema dari tipe Go. Struct bernama disimpan sekali di
+// components/schemas lalu dirujuk lewat $ref.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// of mengembalikan skema untuk contoh nilai v
+func (s *schemas) of(v interface{}) *Schema {
+	if object, ok := v.(Object); ok {
+		return s.object(object)
+	}
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) object(object Object) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for key, value := range object {
+		schema.Properties[key] = s.of(value)
+		schema.Required = append(schema.Required, key)
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIDType:
+		return &Schema{Type: "string", Pattern: objectIDPattern}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.schema(t.Elem())
+		if schema.Ref != "" {
+			// $ref tidak boleh punya saudara di OpenAPI 3.0
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		if t == objectType {
+			return &Schema{Type: "object"}
+		}
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + s.component(t)}
+	}
+	// interface{} dan tipe lain dapat berisi nilai apa saja
+	return &Schema{}
+}
+
+// component mendaftarkan struct bernama t dan mengembalikan nama komponennya
+func (s *schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := s.components[name]; taken {
+		// Nama sama dari package berbeda, mis. model.Response dan response.Envelope
+		pkg := path.Base(t.PkgPath())
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	s.names[t] = name
+	s.components[name] = &Schema{} // Cadangkan nama dulu agar tipe rekursif tidak berputar
+	*s.components[name] = *s.structSchema(t)
+	return name
+}
+
+func (s *schemas) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.fields(t, schema)
+	return schema
+}
+
+// fields menambahkan field JSON dari struct t ke schema, termasuk field embedded
+func (s *schemas) fields(t reflect.Type, schema *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				s.fields(embedded, schema)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.schema(field.Type)
+		if applyRules(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyRules menerjemahkan tag `validate` ke batasan skema dan melaporkan apakah field wajib.
+// Aturan setelah "dive" berlaku untuk elemen slice.
+func applyRules(schema *Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+	rules, elementRules, dive := strings.Cut(tag, ",dive")
+	if dive && schema.Items != nil {
+		applyRules(schema.Items, strings.TrimPrefix(elementRules, ","))
+	}
+	if schema.Ref != "" {
+		// Batasan tidak bisa ditempel pada $ref; cukup laporkan wajib atau tidak
+		for _, rule := range strings.Split(rules, ",") {
+			required = required || rule == "required"
+		}
+		return required
+	}
+
+	for _, rule := range splitRules(rules) {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		case "numeric":
+			schema.Pattern = "^[0-9]+$"
+		case "phone_id":
+			schema.Pattern = validate.PhonePattern
+		case "objectid":
+			schema.Pattern = objectIDPattern
+		case "oneof":
+			for _, value := range validate.OneOfValues(param) {
+				schema.Enum = append(schema.Enum, value)
+			}
+		case "len":
+			setBound(schema, param, true, false)
+			setBound(schema, param, false, false)
+		case "min", "gte":
+			setBound(schema, param, true, false)
+		case "max", "lte":
+			setBound(schema, param, false, false)
+		case "gt":
+			setBound(schema, param, true, true)
+		case "lt":
+			setBound(schema, param, false, true)
+		}
+	}
+	return required
+}
+
+// splitRules memecah aturan dengan koma tanpa memecah nilai oneof yang dikutip
+func splitRules(rules string) []string {
+	var (
+		parts  []string
+		quoted bool
+		start  int
+	)
+	for i, r := range rules {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			parts = append(parts, rules[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, rules[start:])
+}
+
+// setBound memasang batas bawah (lower) atau atas sesuai jenis skema: panjang string,
+// jumlah item array, atau nilai angka
+func setBound(schema *Schema, param string, lower, exclusive bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch schema.Type {
+	case "string":
+		n := int(value)
+		if lower {
+			schema.MinLength = &n
+		} else {
+			schema.MaxLength = &n
+		}
+	case "array":
+		n := int(value)
+		if lower {
+			schema.MinItems = &n
+		} else {
+			schema.MaxItems = &n
+		}
+	case "integer", "number":
+		if lower {
+			schema.Minimum, schema.ExclusiveMinimum = &value, exclusive
+		} else {
+			schema.Maximum, schema.ExclusiveMaximum = &value, exclusive
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package openapi
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+)
+
+//go:embed swagger.html
+var swaggerHTML string
+
+var swaggerPage = template.Must(template.New("swagger").Parse(swaggerHTML))
+
+// UI menyajikan halaman Swagger UI yang memuat dokumen dari specURL
+func UI(specURL string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		swaggerPage.Execute(w, struct{ SpecURL string }{specURL})
+	})
+}
@@ -0,0 +1,112 @@
+package response
+
+import "net/http"
+
+// Code adalah kode error yang dapat dibaca mesin. Nilainya tidak boleh diubah karena
+// dipakai klien untuk menentukan penanganan.
+type Code string
+
+const (
+	CodeBadRequest       Code = "bad_request"
+	CodeInvalidPayload   Code = "invalid_payload"
+	CodeValidation       Code = "validation_failed"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeNotFound         Code = "not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeConflict         Code = "conflict"
+	CodeTooManyRequests  Code = "too_many_requests"
+	CodeInternal         Code = "internal_error"
+	CodeUnavailable      Code = "service_unavailable"
+)
+
+// Error adalah error domain yang sudah tahu status HTTP dan kode error-nya.
+// Title mengisi field "error" dan Message mengisi "message" pada Envelope.
+type Error struct {
+	Status  int
+	Code    Code
+	Title   string
+	Message string
+	Fields  map[string]string // Pesan per field, untuk validasi dan konflik
+	cause   error
+}
+
+// New membuat Error dengan status dan kode sembarang
+func New(status int, code Code, title, message string) *Error {
+	return &Error{Status: status, Code: code, Title: title, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Title + ": " + e.cause.Error()
+	}
+	return e.Title + ": " + e.Message
+}
+
+// Unwrap mengembalikan penyebab asli, jika ada
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// WithFields mengembalikan salinan e dengan pesan per field
+func (e *Error) WithFields(fields map[string]string) *Error {
+	clone := *e
+	clone.Fields = fields
+	return &clone
+}
+
+// WithCause mengembalikan salinan e dengan penyebab asli. Penyebab hanya dicatat ke log,
+// tidak pernah dikirim ke klien.
+func (e *Error) WithCause(err error) *Error {
+	clone := *e
+	clone.cause = err
+	return &clone
+}
+
+// BadRequest untuk parameter yang salah format, mis. ID yang bukan ObjectID
+func BadRequest(title, message string) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, title, message)
+}
+
+// InvalidPayload untuk body JSON yang tidak dapat di-decode
+func InvalidPayload(message string) *Error {
+	return New(http.StatusBadRequest, CodeInvalidPayload, "Invalid request payload", message)
+}
+
+// Validation untuk input yang terbaca tetapi tidak memenuhi aturan
+func Validation(title, message string) *Error {
+	return New(http.StatusBadRequest, CodeValidation, title, message)
+}
+
+// FieldInvalid seperti Validation, untuk satu field; message juga menjadi pesan field tersebut
+func FieldInvalid(field, title, message string) *Error {
+	return Validation(title, message).WithFields(map[string]string{field: message})
+}
+
+func Unauthorized(title, message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, title, message)
+}
+
+func Forbidden(title, message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, title, message)
+}
+
+func NotFound(title, message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, title, message)
+}
+
+func Conflict(title, message string) *Error {
+	return New(http.StatusConflict, CodeConflict, title, message)
+}
+
+func TooManyRequests(title, message string) *Error {
+	return New(http.StatusTooManyRequests, CodeTooManyRequests, title, message)
+}
+
+func Internal(title, message string) *Error {
+	return New(http.StatusInternalServerError, CodeInternal, title, message)
+}
+
+func Unavailable(title, message string) *Error {
+	return New(http.StatusServiceUnavailable, CodeUnavailable, title, message)
+}
@@ -0,0 +1,64 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"plastiqu_co/helper/logger"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Envelope adalah bentuk tunggal setiap respon error API. Error dan Message tetap sama
+// dengan format lama; Code stabil dan dapat diandalkan klien, sedangkan teks bisa berubah.
+type Envelope struct {
+	Error     string            `json:"error"`
+	Code      Code              `json:"code"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// JSON mengirim body sebagai JSON dengan status yang diberikan
+func JSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// WriteError mengirim err dalam Envelope. Error selain *Error dipetakan seperlunya
+// (dokumen tidak ditemukan menjadi 404, duplicate key menjadi 409) dan sisanya menjadi
+// 500 tanpa membocorkan detail; penyebab aslinya dicatat ke log request.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	e := From(err)
+	if e.Status >= http.StatusInternalServerError {
+		attrs := []any{"code", e.Code, "status", e.Status}
+		if cause := e.Unwrap(); cause != nil {
+			attrs = append(attrs, "error", cause)
+		}
+		logger.FromContext(r.Context()).Error(e.Title, attrs...)
+	}
+
+	JSON(w, e.Status, Envelope{
+		Error:     e.Title,
+		Code:      e.Code,
+		Message:   e.Message,
+		Fields:    e.Fields,
+		RequestID: logger.RequestID(r.Context()),
+	})
+}
+
+// From mengubah err apa pun menjadi *Error
+func From(err error) *Error {
+	var e *Error
+	switch {
+	case errors.As(err, &e):
+		return e
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return NotFound("Not found", "The requested resource does not exist.")
+	case mongo.IsDuplicateKeyError(err):
+		return Conflict("Conflict", "A resource with the same unique value already exists.")
+	}
+	return Internal("Internal server error", "An unexpected error occurred.").WithCause(err)
+}
@@ -0,0 +1,86 @@
+package telemetry
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Registry menampung seluruh metrik aplikasi beserta metrik runtime Go dan proses
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	httpRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests processed, by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency, by method and route template.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests currently being served.",
+	})
+
+	dbOperations = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_operations_total",
+		Help: "Database operations, by collection, operation and result (ok, not_found or error).",
+	}, []string{"collection", "operation", "result"})
+
+	dbDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_operation_duration_seconds",
+		Help:    "Database operation latency, by collection and operation.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"collection", "operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler menyajikan metrik dalam format teks Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RequestStarted dipanggil saat request mulai dilayani; fungsi yang dikembalikan
+// dipanggil setelah selesai dengan route template dan status akhirnya
+func RequestStarted() func(method, route string, status int) {
+	start := time.Now()
+	httpInFlight.Inc()
+	return func(method, route string, status int) {
+		httpInFlight.Dec()
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveDB mencatat satu operasi database. mongo.ErrNoDocuments dihitung sebagai
+// not_found, bukan error.
+func ObserveDB(collection, operation string, duration time.Duration, err error) {
+	result := "ok"
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		result = "not_found"
+	case err != nil:
+		result = "error"
+	}
+	dbOperations.WithLabelValues(collection, operation, result).Inc()
+	dbDuration.WithLabelValues(collection, operation).Observe(duration.Seconds())
+}
@@ -0,0 +1,105 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// rfcSecret adalah secret SHA1 dari lampiran B RFC 6238 ("12345678901234567890")
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+// RFC 6238 menerbitkan vektor 8 digit; kode 6 digit adalah enam digit terakhirnya
+func TestCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},          // 94287082
+		{1111111109, "081804"},  // 07081804
+		{1111111111, "050471"},  // 14050471
+		{1234567890, "005924"},  // 89005924
+		{2000000000, "279037"},  // 69279037
+		{20000000000, "353130"}, // 65353130
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+// Vektor HOTP dari lampiran D RFC 4226 dengan secret yang sama
+func TestHOTPRFC4226(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	key := rfcKey(t)
+	for counter, expected := range want {
+		if got := code(key, int64(counter)); got != expected {
+			t.Errorf("counter %d = %s, want %s", counter, got, expected)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+	at := func(offset int64) string {
+		c, err := Code(rfcSecret, now.Add(time.Duration(offset*Period)*time.Second))
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		input    string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", rfcSecret, at(0), current, true},
+		{"previous step within skew", rfcSecret, at(-1), current - 1, true},
+		{"next step within skew", rfcSecret, at(1), current + 1, true},
+		{"outside skew", rfcSecret, at(-2), 0, false},
+		{"surrounding spaces", rfcSecret, " " + at(0) + " ", current, true},
+		{"lowercase secret with spaces", "gezd gnbv gy3t qojq gezd gnbv gy3t qojq", at(0), current, true},
+		{"wrong length", rfcSecret, "12345", 0, false},
+		{"invalid secret", "not base32!", at(0), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(tt.secret, tt.input, now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		t.Fatalf("decode generated secret: %v", err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("secret is %d bytes, want %d", len(key), secretSize)
+	}
+}
+
+func rfcKey(t *testing.T) []byte {
+	t.Helper()
+	key, err := decodeSecret(rfcSecret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+	return key
+}
@@ -0,0 +1,156 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"plastiqu_co/helper/response"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PhonePattern menerima nomor seluler Indonesia: 08xx, 628xx atau +628xx, total 10-15 digit
+const PhonePattern = `^(\+62|62|0)8[1-9][0-9]{6,11}$`
+
+var phonePattern = regexp.MustCompile(PhonePattern)
+
+var v = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Nama field pada error mengikuti tag json agar sama dengan yang dikirim klien
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.RegisterValidation("phone_id", func(fl validator.FieldLevel) bool {
+		return phonePattern.MatchString(fl.Field().String())
+	})
+	// objectid menerima primitive.ObjectID yang terisi atau string hex ObjectID
+	v.RegisterValidation("objectid", func(fl validator.FieldLevel) bool {
+		switch value := fl.Field().Interface().(type) {
+		case primitive.ObjectID:
+			return !value.IsZero()
+		case string:
+			return primitive.IsValidObjectID(value)
+		}
+		return false
+	})
+	return v
+}
+
+// Struct memvalidasi s berdasarkan tag `validate` dan mengembalikan *response.Error
+// berisi semua field yang tidak valid sekaligus, atau nil jika s valid
+func Struct(s interface{}) error {
+	return check(v.Struct(s), false)
+}
+
+// Patch seperti Struct untuk update parsial: field teratas yang kosong dianggap tidak
+// dikirim, sehingga hanya field yang diisi yang diperiksa. Elemen bersarang (mis. varian)
+// tetap harus lengkap.
+func Patch(s interface{}) error {
+	return check(v.Struct(s), true)
+}
+
+func check(err error, partial bool) error {
+	if err == nil {
+		return nil
+	}
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return response.Internal("Failed to validate request", "An error occurred while validating the request.").WithCause(err)
+	}
+
+	fields := map[string]string{}
+	for _, fe := range invalid {
+		path := fieldPath(fe)
+		if partial && fe.Tag() == "required" && !strings.ContainsAny(path, ".[") {
+			continue
+		}
+		fields[path] = message(fe)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return response.Validation("Validation failed", "One or more fields are invalid.").WithFields(fields)
+}
+
+// fieldPath membuang nama struct teratas, mis. "Product.variants[0].price" menjadi "variants[0].price"
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+func message(fe validator.FieldError) string {
+	unit := ""
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "This field is required."
+	case "email":
+		return "Must be a valid email address."
+	case "url":
+		return "Must be a valid URL."
+	case "phone_id":
+		return "Must be an Indonesian mobile number, e.g. 081234567890 or +6281234567890."
+	case "objectid":
+		return "Must be a valid ID."
+	case "numeric":
+		return "Must contain only digits."
+	case "len":
+		return fmt.Sprintf("Must be exactly %s%s long.", fe.Param(), unit)
+	case "min":
+		if unit != "" {
+			return fmt.Sprintf("Must be at least %s%s long.", fe.Param(), unit)
+		}
+		return fmt.Sprintf("Must be at least %s.", fe.Param())
+	case "max":
+		if unit != "" {
+			return fmt.Sprintf("Must be at most %s%s long.", fe.Param(), unit)
+		}
+		return fmt.Sprintf("Must be at most %s.", fe.Param())
+	case "gt":
+		return fmt.Sprintf("Must be greater than %s.", fe.Param())
+	case "gte":
+		return fmt.Sprintf("Must be greater than or equal to %s.", fe.Param())
+	case "lt":
+		return fmt.Sprintf("Must be less than %s.", fe.Param())
+	case "lte":
+		return fmt.Sprintf("Must be less than or equal to %s.", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("Must be one of: %s.", strings.Join(OneOfValues(fe.Param()), ", "))
+	}
+	return "Is invalid."
+}
+
+// OneOfValues memecah parameter oneof; nilai yang mengandung spasi ditulis dalam kutip tunggal
+func OneOfValues(param string) []string {
+	if !strings.Contains(param, "'") {
+		return strings.Fields(param)
+	}
+	var values []string
+	for _, value := range strings.Split(param, "'") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
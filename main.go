@@ -1,44 +1,130 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"plastiqu_co/config"
+	"plastiqu_co/controller/auth"
+	"plastiqu_co/helper/atdb"
+	"plastiqu_co/helper/logger"
+	"plastiqu_co/helper/metric"
+	"plastiqu_co/helper/telemetry"
+	"plastiqu_co/middleware"
+	"plastiqu_co/repository"
 	"plastiqu_co/routes"
 
 	"github.com/rs/cors"
 )
 
 func main() {
-	connectdb := config.Mongoconn
-
-	if config.ErrorMongoconn != nil {
-		fmt.Println("Failed to connect to MongoDB:", config.ErrorMongoconn)
+	if len(os.Args) > 1 && runCommand(os.Args[1:]) {
 		return
 	}
 
-	// Check if the connection is successful
-	if connectdb != nil {
-		fmt.Println("Successfully connected to MongoDB!")
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	// Structured logs: JSON in production, text for local development
+	log := logger.New(os.Stdout, cfg.Level(), cfg.Env == config.EnvProduction)
+	slog.SetDefault(log)
+
+	db, err := config.Connect(cfg)
+	if err != nil {
+		log.Error("failed to connect to MongoDB", "error", err)
+		os.Exit(1)
+	}
+	if cfg.Database == config.DatabaseMemory {
+		log.Warn("using in-memory database, data will be lost on shutdown")
 	} else {
-		fmt.Println("MongoDB connection is nil")
+		log.Info("connected to MongoDB", "database", cfg.DBName)
 	}
 
-	router := routes.InitializeRoutes()
+	// Load the token signing keys shared by every instance
+	keyRing, err := metric.LoadKeyRing(cfg.PasetoKeysFile, cfg.PasetoKeys, cfg.PasetoActiveKey)
+	if err != nil {
+		log.Error("failed to load token keys", "error", err)
+		os.Exit(1)
+	}
+	metric.SetKeyRing(keyRing)
+	log.Info("token key ring loaded", "active_key", keyRing.ActiveKeyID())
+
+	// Every collection operation is timed and counted for /metrics
+	repos := repository.New(atdb.Observe(db, telemetry.ObserveDB))
 
+	// Apply pending index and data migrations; disable with MIGRATE_ON_START=false and run `migrate up` instead
+	if cfg.MigrateOnStart {
+		migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), migrateTimeout)
+		if _, err := repos.Migrate(migrateCtx); err != nil {
+			log.Error("failed to apply migrations", "error", err)
+			os.Exit(1)
+		}
+		cancelMigrate()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Seed built-in roles and their default permissions
+	if err := repos.Roles.EnsureDefaults(ctx); err != nil {
+		log.Error("failed to seed default roles", "error", err)
+	}
+	cancel()
+
+	router := routes.InitializeRoutes(repos, auth.Mail{Sender: cfg.Mailer(), BaseURL: cfg.BaseURL, FrontendURL: cfg.FrontendURL}, cfg.Location())
+
+	// Credentials are only allowed when the allowed origins are listed explicitly
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
-		AllowCredentials: true,
-		Debug:            true,
+		AllowedOrigins:   cfg.CORSOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", middleware.RequestIDHeader},
+		ExposedHeaders:   []string{middleware.RequestIDHeader},
+		AllowCredentials: !cfg.AllowsAnyOrigin(),
+		Debug:            cfg.Env == config.EnvDevelopment,
 	})
 
-	handler := c.Handler(router)
-	// Initialize the router from the routes package
+	// Request IDs, access logs and metrics wrap everything, including CORS preflight and 404s.
+	// The client IP is resolved first; X-Forwarded-For is only honoured from TRUSTED_PROXIES.
+	handler := middleware.RealIP(cfg.TrustedProxyNetworks())(middleware.RequestLogger(log)(middleware.Metrics(router)(c.Handler(router))))
+
+	server := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+
+	// Stop on SIGINT/SIGTERM: stop accepting connections, let in-flight requests finish, then disconnect
+	stop, release := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer release()
 
-	fmt.Println("Server is running on http://localhost:3600")
-	log.Fatal(http.ListenAndServe(":3600", handler))
+	go func() {
+		log.Info("server is running", "url", cfg.BaseURL)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("server failed", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	<-stop.Done()
+	log.Info("shutting down server")
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to drain in-flight requests", "error", err)
+	}
+	if err := db.Disconnect(shutdownCtx); err != nil {
+		log.Error("failed to disconnect from the database", "error", err)
+	}
+	log.Info("server stopped")
 }
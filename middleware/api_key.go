@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"plastiqu_co/helper/logger"
+	"plastiqu_co/helper/metric"
+	"plastiqu_co/helper/response"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// apiKeyTouchInterval membatasi seberapa sering last_used_at ditulis ulang
+const apiKeyTouchInterval = time.Minute
+
+var (
+	errAPIKeyInvalid = errors.New("api key is invalid")
+	errAPIKeyRevoked = errors.New("api key has been revoked")
+	errAPIKeyExpired = errors.New("api key has expired")
+)
+
+// apiKeyErrorMessages adalah pesan 401 untuk setiap alasan API key ditolak
+var apiKeyErrorMessages = map[error]string{
+	errAPIKeyInvalid: "The provided API key is invalid.",
+	errAPIKeyRevoked: "The provided API key has been revoked.",
+	errAPIKeyExpired: "The provided API key has expired.",
+}
+
+// AuthenticateOrAPIKey seperti Authenticate, tetapi juga menerima header
+// "Authorization: ApiKey <key>" untuk integrasi antar server. Principal hasil API key
+// tidak memiliki UserID dan hanya memiliki permission sesuai scope kunci.
+func (g *Guard) AuthenticateOrAPIKey(next http.Handler) http.Handler {
+	users := g.Authenticate(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := credentials(r, "ApiKey")
+		if !ok {
+			users.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := g.apiKeyPrincipal(r.Context(), key)
+		if message, rejected := apiKeyErrorMessages[err]; rejected {
+			response.WriteError(w, r, response.Unauthorized("Unauthorized", message))
+			return
+		}
+		if err != nil {
+			response.WriteError(w, r, response.Internal("Failed to verify API key", "An error occurred while verifying the API key.").WithCause(err))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// apiKeyPrincipal mencari API key berdasarkan hash-nya dan mencatat waktu pemakaiannya
+func (g *Guard) apiKeyPrincipal(ctx context.Context, key string) (Principal, error) {
+	apiKey, err := g.apiKeys.FindByHash(ctx, metric.HashToken(key))
+	if err == mongo.ErrNoDocuments {
+		return Principal{}, errAPIKeyInvalid
+	} else if err != nil {
+		return Principal{}, err
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil {
+		return Principal{}, errAPIKeyRevoked
+	}
+	if apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt) {
+		return Principal{}, errAPIKeyExpired
+	}
+
+	// Cukup akurat sampai hitungan menit, tanpa menulis ke database di setiap request
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		if _, err := g.apiKeys.UpdateByID(ctx, apiKey.ID, bson.M{"$set": bson.M{"last_used_at": now}}); err != nil {
+			// Request tetap dilayani; hanya last_used_at yang tertinggal
+			logger.FromContext(ctx).Error("failed to record API key usage", "api_key_id", apiKey.ID.Hex(), "error", err)
+		}
+	}
+
+	return Principal{APIKeyID: apiKey.ID, Scopes: apiKey.Permissions}, nil
+}
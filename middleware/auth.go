@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"plastiqu_co/helper/metric"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/model"
+	"plastiqu_co/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Principal adalah identitas pemanggil yang sudah terautentikasi, baik pengguna
+// (UserID terisi) maupun API key integrasi (APIKeyID terisi)
+type Principal struct {
+	UserID    primitive.ObjectID
+	Role      string             // Dibaca dari data user pada setiap request, bukan dari klaim token
+	SessionID primitive.ObjectID // Kosong untuk token pendaftaran 2FA
+	APIKeyID  primitive.ObjectID
+	Scopes    []string // Permission milik API key
+}
+
+type contextKey int
+
+const principalKey contextKey = iota
+
+// WithPrincipal menyimpan principal ke dalam context dan mencatatnya untuk log akses
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	if info, ok := ctx.Value(requestInfoKey).(*requestInfo); ok {
+		info.principal = &principal
+	}
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext mengambil principal yang disisipkan oleh Authenticate
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	return principal, ok
+}
+
+// Guard adalah middleware autentikasi dan otorisasi. Sesi, API key dan role
+// dibaca dari repository yang diberikan ke NewGuard.
+type Guard struct {
+	users    repository.UserRepository
+	sessions repository.SessionRepository
+	apiKeys  repository.APIKeyRepository
+	roles    repository.RoleRepository
+
+	roleCacheMu sync.RWMutex
+	roleCache   map[string]cachedRole
+}
+
+// NewGuard membuat Guard di atas repository aplikasi
+func NewGuard(repos *repository.Repositories) *Guard {
+	return &Guard{
+		users:     repos.Users,
+		sessions:  repos.Sessions,
+		apiKeys:   repos.APIKeys,
+		roles:     repos.Roles,
+		roleCache: map[string]cachedRole{},
+	}
+}
+
+// Authenticate memverifikasi bearer token pada header Authorization dan
+// menyisipkan principal ke context request. Request tanpa token yang valid
+// ditolak dengan 401.
+func (g *Guard) Authenticate(next http.Handler) http.Handler {
+	return g.authenticate(next)
+}
+
+// AuthenticateEnrollment seperti Authenticate, tetapi juga menerima token pendaftaran 2FA
+// yang diterbitkan saat role pengguna mewajibkan 2FA dan pengguna belum mendaftar.
+func (g *Guard) AuthenticateEnrollment(next http.Handler) http.Handler {
+	return g.authenticate(next, metric.PurposeTwoFactorEnrollment)
+}
+
+// authenticate menerima access token biasa dan token dengan purpose yang diizinkan
+func (g *Guard) authenticate(next http.Handler, purposes ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := credentials(r, "Bearer")
+		if !ok {
+			response.WriteError(w, r, response.Unauthorized("Unauthorized", "A valid bearer token is required."))
+			return
+		}
+
+		payload, err := metric.DecodeToken(token)
+		if err != nil {
+			message := "The provided token is invalid."
+			if errors.Is(err, metric.ErrExpiredToken) {
+				message = "The provided token has expired."
+			}
+			response.WriteError(w, r, response.Unauthorized("Unauthorized", message))
+			return
+		}
+
+		if !allowedPurpose(payload.Purpose, purposes) {
+			response.WriteError(w, r, response.Unauthorized("Unauthorized", "The provided token cannot be used for this request."))
+			return
+		}
+
+		userID, err := primitive.ObjectIDFromHex(payload.UserID)
+		if err != nil {
+			response.WriteError(w, r, response.Unauthorized("Unauthorized", "The provided token is invalid."))
+			return
+		}
+
+		principal := Principal{UserID: userID}
+
+		// Access token biasa harus berasal dari sesi yang belum dicabut
+		if payload.Purpose == "" {
+			principal.SessionID, err = primitive.ObjectIDFromHex(payload.SessionID)
+			if err != nil {
+				response.WriteError(w, r, response.Unauthorized("Unauthorized", "The provided token is invalid."))
+				return
+			}
+			active, err := g.sessions.IsActive(r.Context(), principal.SessionID, userID)
+			if err != nil {
+				response.WriteError(w, r, response.Internal("Failed to verify session", "An error occurred while verifying your session.").WithCause(err))
+				return
+			}
+			if !active {
+				response.WriteError(w, r, response.Unauthorized("Unauthorized", "The session has been signed out."))
+				return
+			}
+		}
+
+		// Role diambil dari data user agar perubahan role dan status langsung berlaku,
+		// tanpa menunggu access token kedaluwarsa
+		user, err := g.users.FindOne(r.Context(), bson.M{"_id": userID}, options.FindOne().SetProjection(bson.M{"role": 1, "status": 1}))
+		if err == mongo.ErrNoDocuments || user.Status == model.UserStatusSuspended || user.Status == model.UserStatusDeleted {
+			response.WriteError(w, r, response.Unauthorized("Unauthorized", "The account is no longer active."))
+			return
+		} else if err != nil {
+			response.WriteError(w, r, response.Internal("Failed to verify session", "An error occurred while verifying your session.").WithCause(err))
+			return
+		}
+		principal.Role = user.Role
+
+		ctx := WithPrincipal(r.Context(), principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func allowedPurpose(purpose string, allowed []string) bool {
+	if purpose == "" {
+		return true
+	}
+	for _, p := range allowed {
+		if p == purpose {
+			return true
+		}
+	}
+	return false
+}
+
+// credentials mengambil kredensial dari header Authorization dengan skema tertentu, mis. "Bearer"
+func credentials(r *http.Request, scheme string) (string, bool) {
+	header := r.Header.Get("Authorization")
+	prefix, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(prefix, scheme) {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
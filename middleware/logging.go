@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"plastiqu_co/helper/logger"
+)
+
+// RequestIDHeader membawa ID korelasi request dari klien atau proxy ke respon dan log
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern membatasi ID dari luar agar tidak bisa menyisipkan isi sembarang ke log
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestInfo diisi selama request berjalan sehingga log akses bisa mencatat pemanggil
+// yang baru diketahui setelah middleware autentikasi di dalam router
+type requestInfo struct {
+	principal *Principal
+}
+
+const requestInfoKey contextKey = principalKey + 1
+
+// RequestLogger memberi setiap request ID (memakai X-Request-ID dari klien jika valid),
+// menyisipkan logger ber-request_id ke context, lalu mencatat method, path, status,
+// latensi dan pemanggil setelah request selesai
+func RequestLogger(base *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			id := r.Header.Get(RequestIDHeader)
+			if !requestIDPattern.MatchString(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			info := &requestInfo{}
+			log := base.With("request_id", id)
+			ctx := context.WithValue(r.Context(), requestInfoKey, info)
+			ctx = logger.WithRequestID(logger.WithContext(ctx, log), id)
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			attrs := []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", recorder.status,
+				"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+				"bytes", recorder.bytes,
+				"ip", ClientIP(r),
+			}
+			if p := info.principal; p != nil {
+				if !p.UserID.IsZero() {
+					attrs = append(attrs, "user_id", p.UserID.Hex())
+				}
+				if !p.APIKeyID.IsZero() {
+					attrs = append(attrs, "api_key_id", p.APIKeyID.Hex())
+				}
+			}
+
+			level := slog.LevelInfo
+			if recorder.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			log.Log(r.Context(), level, "request", attrs...)
+		})
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// statusRecorder mencatat status dan jumlah byte yang ditulis handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+// Unwrap membuat http.ResponseController tetap dapat menjangkau writer aslinya
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"plastiqu_co/helper/telemetry"
+
+	"github.com/gorilla/mux"
+)
+
+// unmatchedRoute adalah label route untuk request yang tidak cocok dengan route mana pun,
+// agar path acak tidak membuat label metrik baru
+const unmatchedRoute = "unmatched"
+
+// Metrics mencatat jumlah request, latensi dan request yang sedang berjalan per route.
+// Label route memakai template mux (mis. /products/{id}), bukan path mentah.
+func Metrics(router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			done := telemetry.RequestStarted()
+
+			route := unmatchedRoute
+			var match mux.RouteMatch
+			if router.Match(r, &match) && match.Route != nil {
+				if template, err := match.Route.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				done(r.Method, route, recorder.status)
+			}()
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
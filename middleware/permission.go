@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"plastiqu_co/helper/response"
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// roleCacheTTL membatasi berapa lama permission sebuah role disimpan di memori
+const roleCacheTTL = 30 * time.Second
+
+type cachedRole struct {
+	role     model.Role
+	loadedAt time.Time
+}
+
+// InvalidateRoleCache menghapus cache role sehingga perubahan permission langsung berlaku
+func (g *Guard) InvalidateRoleCache(name string) {
+	g.roleCacheMu.Lock()
+	delete(g.roleCache, name)
+	g.roleCacheMu.Unlock()
+}
+
+// loadRole mengambil role dari koleksi roles, dengan cache singkat di memori.
+// Role yang tidak ditemukan dianggap tidak memiliki permission apa pun.
+func (g *Guard) loadRole(ctx context.Context, name string) (model.Role, error) {
+	g.roleCacheMu.RLock()
+	cached, ok := g.roleCache[name]
+	g.roleCacheMu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < roleCacheTTL {
+		return cached.role, nil
+	}
+
+	role, err := g.roles.FindByName(ctx, name)
+	if err == mongo.ErrNoDocuments {
+		role = model.Role{Name: name}
+	} else if err != nil {
+		return model.Role{}, err
+	}
+
+	g.roleCacheMu.Lock()
+	g.roleCache[name] = cachedRole{role: role, loadedAt: time.Now()}
+	g.roleCacheMu.Unlock()
+	return role, nil
+}
+
+// RoleRequiresTwoFactor mengecek apakah kebijakan role mewajibkan 2FA
+func (g *Guard) RoleRequiresTwoFactor(ctx context.Context, name string) (bool, error) {
+	role, err := g.loadRole(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return role.RequireTwoFactor, nil
+}
+
+// HasPermission mengecek apakah principal pada context memiliki permission tertentu.
+// Admin selalu memiliki seluruh permission.
+func (g *Guard) HasPermission(ctx context.Context, permission string) (bool, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return false, nil
+	}
+	// API key hanya memiliki scope yang diberikan saat dibuat
+	if !principal.APIKeyID.IsZero() {
+		for _, scope := range principal.Scopes {
+			if scope == permission {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if principal.Role == model.RoleAdmin {
+		return true, nil
+	}
+
+	role, err := g.loadRole(ctx, principal.Role)
+	if err != nil {
+		return false, err
+	}
+	return role.HasPermission(permission), nil
+}
+
+// RequirePermission hanya meneruskan request dari principal yang role-nya memiliki
+// permission tertentu. Harus dipasang setelah Authenticate.
+func (g *Guard) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := PrincipalFromContext(r.Context()); !ok {
+				response.WriteError(w, r, response.Unauthorized("Unauthorized", "A valid bearer token is required."))
+				return
+			}
+
+			allowed, err := g.HasPermission(r.Context(), permission)
+			if err != nil {
+				response.WriteError(w, r, response.Internal("Failed to check permission", "An error occurred while checking your permissions.").WithCause(err))
+				return
+			}
+			if !allowed {
+				writeForbidden(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeForbidden(w http.ResponseWriter, r *http.Request) {
+	response.WriteError(w, r, response.Forbidden("Access denied", "You do not have permission to perform this action."))
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const clientIPKey contextKey = principalKey + 2
+
+// RealIP menentukan IP pemanggil sekali per request lalu menyimpannya untuk ClientIP.
+// X-Forwarded-For hanya dipercaya jika request datang dari proxy di trusted: header dibaca
+// dari kanan ke kiri dan alamat pertama yang bukan proxy tepercaya dianggap sebagai klien.
+// Tanpa proxy tepercaya, header tersebut diabaikan karena nilainya dikendalikan klien.
+func RealIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := remoteIP(r)
+			if isTrusted(ip, trusted) {
+				hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+				for i := len(hops) - 1; i >= 0; i-- {
+					hop := strings.TrimSpace(hops[i])
+					if net.ParseIP(hop) == nil {
+						break
+					}
+					ip = hop
+					if !isTrusted(hop, trusted) {
+						break
+					}
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey, ip)))
+		})
+	}
+}
+
+// ClientIP mengambil IP pemanggil yang ditentukan RealIP, atau alamat koneksi langsung
+// jika RealIP tidak dipasang
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func isTrusted(ip string, trusted []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
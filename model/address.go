@@ -5,14 +5,14 @@ import "go.mongodb.org/mongo-driver/bson/primitive"
 // Address represents an address model with the required fields
 type Address struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	FullName    string             `bson:"full_name" json:"full_name"`
-	Phone       string             `bson:"phone" json:"phone"`
-	Province    string             `bson:"province" json:"province"`
-	City        string             `bson:"city" json:"city"`
-	Subdistrict string             `bson:"subdistrict" json:"subdistrict"`
-	PostalCode  string             `bson:"postal_code" json:"postal_code"`
-	StreetName  string             `bson:"street_name" json:"street_name"`
-	Building    string             `bson:"building,omitempty" json:"building,omitempty"`      // Optional field
-	HouseNumber string             `bson:"house_number,omitempty" json:"house_number,omitempty"` // Optional field
-	Label       string             `bson:"label" json:"label"` // e.g., "kantor" or "rumah"
+	FullName    string             `bson:"full_name" json:"full_name" validate:"required,max=100"`
+	Phone       string             `bson:"phone" json:"phone" validate:"required,phone_id"`
+	Province    string             `bson:"province" json:"province" validate:"required,max=100"`
+	City        string             `bson:"city" json:"city" validate:"required,max=100"`
+	Subdistrict string             `bson:"subdistrict" json:"subdistrict" validate:"required,max=100"`
+	PostalCode  string             `bson:"postal_code" json:"postal_code" validate:"required,numeric,len=5"`
+	StreetName  string             `bson:"street_name" json:"street_name" validate:"required,max=200"`
+	Building    string             `bson:"building,omitempty" json:"building,omitempty" validate:"max=100"`      // Optional field
+	HouseNumber string             `bson:"house_number,omitempty" json:"house_number,omitempty" validate:"max=20"` // Optional field
+	Label       string             `bson:"label" json:"label" validate:"required,max=30"` // e.g., "kantor" or "rumah"
 }
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey adalah kredensial untuk integrasi antar server (mis. sistem gudang atau skrip akuntansi).
+// Kunci lengkap hanya ditampilkan sekali saat dibuat; yang disimpan adalah hash-nya.
+type APIKey struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	Prefix      string             `bson:"prefix" json:"prefix"` // Awal kunci untuk mengenali kunci tanpa membuka rahasianya
+	KeyHash     string             `bson:"key_hash" json:"-"`
+	Permissions []string           `bson:"permissions" json:"permissions"` // Scope, diambil dari model.Permissions
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	ExpiresAt   *time.Time         `bson:"expires_at,omitempty" json:"expires_at,omitempty"` // Kosong berarti tidak kedaluwarsa
+	LastUsedAt  *time.Time         `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// HasPermission mengecek apakah scope API key mencakup permission tertentu
+func (k APIKey) HasPermission(permission string) bool {
+	for _, p := range k.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLog adalah satu entri jejak audit untuk aksi administratif atau sensitif.
+// Entri hanya ditambahkan, tidak pernah diubah maupun dihapus.
+type AuditLog struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ActorID   *primitive.ObjectID `bson:"actor_id,omitempty" json:"actor_id,omitempty"`
+	ActorRole string              `bson:"actor_role,omitempty" json:"actor_role,omitempty"`
+	APIKeyID  *primitive.ObjectID `bson:"api_key_id,omitempty" json:"api_key_id,omitempty"` // Diisi jika aksi dilakukan lewat API key
+	Action    string              `bson:"action" json:"action"`                             // mis. "product.delete", "user.role.update"
+	Entity    string              `bson:"entity" json:"entity"`                             // Nama koleksi target, mis. "products"
+	EntityID  string              `bson:"entity_id" json:"entity_id"`                       // ID dokumen target
+	Changes   []AuditChange       `bson:"changes,omitempty" json:"changes,omitempty"`
+	Request   AuditRequest        `bson:"request" json:"request"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// AuditChange mencatat nilai sebuah field sebelum dan sesudah aksi
+type AuditChange struct {
+	Field  string      `bson:"field" json:"field"`
+	Before interface{} `bson:"before,omitempty" json:"before,omitempty"`
+	After  interface{} `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// AuditRequest menyimpan metadata request yang memicu aksi
+type AuditRequest struct {
+	Method    string `bson:"method" json:"method"`
+	Path      string `bson:"path" json:"path"`
+	IP        string `bson:"ip,omitempty" json:"ip,omitempty"`
+	UserAgent string `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+}
@@ -6,14 +6,95 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Role bawaan untuk model.Users.Role
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Status akun pada model.Users.Status. Status kosong dianggap aktif (akun lama).
+const (
+	UserStatusPendingVerification = "pending_verification"
+	UserStatusActive              = "active"
+	UserStatusSuspended           = "suspended"
+	UserStatusDeleted             = "deleted" // Soft delete, data tetap disimpan
+)
+
 type Users struct {
 	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Username       string             `bson:"username,omitempty" json:"username,omitempty"`
-	Email           string             `bson:"email,omitempty" json:"email,omitempty"`
+	Username       string             `bson:"username,omitempty" json:"username,omitempty" validate:"required,min=3,max=32"`
+	Email           string             `bson:"email,omitempty" json:"email,omitempty" validate:"required,email"`
 	Role            string             `bson:"role,omitempty" json:"role,omitempty"` // Default "user"
-	Phone           string             `bson:"phone,omitempty" json:"phone,omitempty"`
-	Password        string             `bson:"password,omitempty" json:"password,omitempty"`
+	Phone           string             `bson:"phone,omitempty" json:"phone,omitempty" validate:"required,phone_id"`
+	Password        string             `bson:"password,omitempty" json:"password,omitempty" validate:"required,min=8,max=72"`
 	Image           string             `bson:"image,omitempty" json:"image,omitempty"` // Nullable, URL or base64 for image
-	CreatedAt       time.Time          `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
-	UpdatedAt       time.Time          `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+	Status          string             `bson:"status,omitempty" json:"status,omitempty"`
+	EmailVerifiedAt *time.Time         `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
+	SuspendedAt     *time.Time         `bson:"suspended_at,omitempty" json:"suspended_at,omitempty"`
+	SuspendReason   string             `bson:"suspend_reason,omitempty" json:"suspend_reason,omitempty"`
+	DeletedAt       *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DuplicateEmail  string             `bson:"duplicate_email,omitempty" json:"duplicate_email,omitempty"` // Diisi migration dedupe untuk akun duplikat yang ditangguhkan
+	TwoFactor       *TwoFactor         `bson:"two_factor,omitempty" json:"two_factor,omitempty"`
+	CreatedAt       time.Time          `bson:"created_at,omitempty" json:"createdAt,omitempty"`
+	UpdatedAt       time.Time          `bson:"updated_at,omitempty" json:"updatedAt,omitempty"`
+}
+
+// TwoFactor menyimpan status TOTP pengguna. Secret dan kode pemulihan tidak pernah dikirim ke klien.
+type TwoFactor struct {
+	Enabled       bool       `bson:"enabled" json:"enabled"`
+	Secret        string     `bson:"secret,omitempty" json:"-"`
+	PendingSecret string     `bson:"pending_secret,omitempty" json:"-"` // Secret yang sedang didaftarkan, belum dikonfirmasi
+	RecoveryCodes []string   `bson:"recovery_codes,omitempty" json:"-"` // Hash SHA-256 dari kode pemulihan yang belum terpakai
+	LastStep      int64      `bson:"last_step,omitempty" json:"-"`      // Langkah waktu TOTP terakhir yang dipakai, mencegah replay
+	EnabledAt     *time.Time `bson:"enabled_at,omitempty" json:"enabled_at,omitempty"`
+}
+
+// RefreshToken menyimpan hash refresh token beserta keluarga rotasinya
+type RefreshToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	FamilyID  primitive.ObjectID `bson:"family_id" json:"family_id"` // Semua token hasil rotasi dari satu login berbagi family yang sama
+	TokenHash string             `bson:"token_hash" json:"-"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	RotatedAt *time.Time         `bson:"rotated_at,omitempty" json:"rotated_at,omitempty"` // Diisi saat token ditukar dengan token baru
+	RevokedAt *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// EmailVerification menyimpan hash token sekali pakai untuk verifikasi email
+type EmailVerification struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Email     string             `bson:"email" json:"email"`
+	TokenHash string             `bson:"token_hash" json:"-"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty" json:"used_at,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// VerificationRequest mencatat satu permintaan kirim ulang email verifikasi. Dicatat per email,
+// terdaftar atau tidak, sehingga pembatasan frekuensi tidak membocorkan email yang terdaftar.
+type VerificationRequest struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Email     string             `bson:"email" json:"email"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// PasswordReset menyimpan hash token sekali pakai untuk reset password
+type PasswordReset struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	TokenHash string             `bson:"token_hash" json:"-"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty" json:"used_at,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// LoginAttempt mencatat login gagal per akun ("email:<email>") atau per IP ("ip:<ip>")
+type LoginAttempt struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Key           string             `bson:"key" json:"key"`
+	Failures      int                `bson:"failures" json:"failures"`
+	LastFailureAt time.Time          `bson:"last_failure_at" json:"last_failure_at"`
+	LockedUntil   *time.Time         `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
 }
@@ -7,10 +7,10 @@ import (
 )
 type Cart struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	UserID    primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"` // Foreign Key ke tabel Users
-	ProductID primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty"` // Foreign Key ke tabel Product
-	Variant   string             `bson:"variant,omitempty" json:"variant,omitempty"` // Nullable
-	Amount    int                `bson:"amount,omitempty" json:"amount,omitempty"`   // Jumlah produk
+	UserID    primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty" validate:"required,objectid"` // Foreign Key ke tabel Users
+	ProductID primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty" validate:"required,objectid"` // Foreign Key ke tabel Product
+	Variant   string             `bson:"variant,omitempty" json:"variant,omitempty" validate:"max=100"` // Nullable
+	Amount    int                `bson:"amount,omitempty" json:"amount,omitempty" validate:"required,min=1,max=999"`   // Jumlah produk
 	CreatedAt time.Time          `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
 	UpdatedAt time.Time          `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
 }
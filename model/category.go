@@ -4,5 +4,5 @@ import "go.mongodb.org/mongo-driver/bson/primitive"
 
 type Category struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	CategoryName string             `bson:"name_category" json:"name_category"`
+	CategoryName string             `bson:"name_category" json:"name_category" validate:"required,max=100"`
 }
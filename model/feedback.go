@@ -48,12 +48,19 @@ type TopSellingProduct struct {
 }
 
 
-// Session struct untuk merepresentasikan sesi pengguna untuk pelacakan
+// Session struct untuk merepresentasikan sesi login pengguna. ID sesi sama dengan
+// family refresh token yang diterbitkan saat login.
 type Session struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"` // ID sesi
-	IP       string             `bson:"ip" json:"ip"`            // IP pengguna
-	Duration int                `bson:"duration" json:"duration"` // Durasi sesi dalam detik
-	Date     time.Time          `bson:"date" json:"date"`        // Tanggal dan waktu sesi
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`                          // ID sesi
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`                           // Pemilik sesi
+	IP         string             `bson:"ip" json:"ip"`                                     // IP pengguna
+	UserAgent  string             `bson:"user_agent,omitempty" json:"user_agent,omitempty"` // Perangkat/browser saat login
+	Duration   int                `bson:"duration" json:"duration"`                         // Durasi sesi dalam detik, diisi saat sesi berakhir
+	Date       time.Time          `bson:"date" json:"date"`                                 // Tanggal dan waktu sesi dimulai
+	LastSeenAt time.Time          `bson:"last_seen_at" json:"last_seen_at"`                 // Terakhir kali token sesi di-refresh
+	ExpiresAt  time.Time          `bson:"expires_at" json:"expires_at"`                     // Sesi berakhir jika tidak di-refresh sampai waktu ini
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"` // Diisi saat logout atau dicabut
+	Current    bool               `bson:"-" json:"current"`                                 // Sesi milik token yang sedang dipakai
 }
 
 // AbandonedCart struct untuk merepresentasikan pelacakan keranjang yang ditinggalkan
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// SchemaMigration mencatat migration yang sudah diterapkan pada koleksi schema_migrations.
+// _id berisi nomor versi sehingga satu versi tidak mungkin tercatat dua kali.
+type SchemaMigration struct {
+	Version    int       `bson:"_id" json:"version"`
+	Name       string    `bson:"name" json:"name"`
+	AppliedAt  time.Time `bson:"applied_at" json:"applied_at"`
+	DurationMS int64     `bson:"duration_ms" json:"duration_ms"`
+}
@@ -8,19 +8,19 @@ import (
 
 type Orders struct {
 	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	UserID         primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"`       // Foreign Key ke tabel Users
-	ProductID      primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty"` // Foreign Key ke tabel Product
+	UserID         primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty" validate:"required,objectid"`       // Foreign Key ke tabel Users
+	ProductID      primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty" validate:"required,objectid"` // Foreign Key ke tabel Product
 	OrderCode      string             `bson:"order_code,omitempty" json:"order_code,omitempty"` // Kode pemesanan yang unik
-	Fullname       string             `bson:"fullname,omitempty" json:"fullname,omitempty"`
-	Phone          string             `bson:"phone,omitempty" json:"phone,omitempty"`
-	Address        string             `bson:"address,omitempty" json:"address,omitempty"`
-	ProductName    string             `bson:"product_name,omitempty" json:"product_name,omitempty"`
-	Variant        string             `bson:"variant,omitempty" json:"variant,omitempty"` // Nullable
-	Amount         int                `bson:"amount,omitempty" json:"amount,omitempty"`   // Jumlah produk
-	Price          float64            `bson:"price,omitempty" json:"price,omitempty"`
-	TotalPrice     float64            `bson:"total_price,omitempty" json:"total_price,omitempty"` // Total keseluruhan
-	Status         string             `bson:"status,omitempty" json:"status,omitempty"`           // Belum Bayar, Menunggu Konfirmasi, Diproses, Dikemas, Dikirim, Selesai, Dibatalkan
-	PaymentMethod  string             `bson:"payment_method,omitempty" json:"payment_method,omitempty"` // "transfer" atau "COD"
+	Fullname       string             `bson:"fullname,omitempty" json:"fullname,omitempty" validate:"required,max=100"`
+	Phone          string             `bson:"phone,omitempty" json:"phone,omitempty" validate:"required,phone_id"`
+	Address        string             `bson:"address,omitempty" json:"address,omitempty" validate:"required,max=500"`
+	ProductName    string             `bson:"product_name,omitempty" json:"product_name,omitempty" validate:"required,max=150"`
+	Variant        string             `bson:"variant,omitempty" json:"variant,omitempty" validate:"max=100"` // Nullable
+	Amount         int                `bson:"amount,omitempty" json:"amount,omitempty" validate:"required,min=1,max=999"`   // Jumlah produk
+	Price          float64            `bson:"price,omitempty" json:"price,omitempty" validate:"required,gt=0"`
+	TotalPrice     float64            `bson:"total_price,omitempty" json:"total_price,omitempty" validate:"gte=0"` // Total keseluruhan
+	Status         string             `bson:"status,omitempty" json:"status,omitempty" validate:"omitempty,oneof='Belum Bayar' 'Menunggu Konfirmasi' 'Sudah Bayar' 'Diproses' 'Dikemas' 'Dikirim' 'Selesai' 'Dibatalkan'"`           // Belum Bayar, Menunggu Konfirmasi, Diproses, Dikemas, Dikirim, Selesai, Dibatalkan
+	PaymentMethod  string             `bson:"payment_method,omitempty" json:"payment_method,omitempty" validate:"required,oneof=transfer COD"` // "transfer" atau "COD"
 	PaymentProof   string             `bson:"payment_proof,omitempty" json:"payment_proof,omitempty"`   // URL Bukti Pembayaran (untuk transfer)
 	ProductImages  []string           `bson:"product_images,omitempty" json:"product_images,omitempty"` // Slice untuk menyimpan URL gambar produk
 	CreatedAt      time.Time          `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
@@ -6,7 +6,7 @@ import (
 
 type PaymentDetails struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Bank         string             `bson:"bank,omitempty" json:"bank,omitempty"`               // Nama Bank
-	AccountName  string             `bson:"account_name,omitempty" json:"account_name,omitempty"` // Nama Pemilik Rekening
-	AccountNumber string            `bson:"account_number,omitempty" json:"account_number,omitempty"` // Nomor Rekening
+	Bank         string             `bson:"bank,omitempty" json:"bank,omitempty" validate:"required,max=50"`               // Nama Bank
+	AccountName  string             `bson:"account_name,omitempty" json:"account_name,omitempty" validate:"required,max=100"` // Nama Pemilik Rekening
+	AccountNumber string            `bson:"account_number,omitempty" json:"account_number,omitempty" validate:"required,numeric,min=5,max=20"` // Nomor Rekening
 }
@@ -8,11 +8,11 @@ import (
 // Review struct untuk menyimpan ulasan produk
 type Review struct {
 	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"` // ID ulasan
-	ProductID      primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty"` // Foreign Key ke tabel Product
-	UserID         primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"` // Foreign Key ke user yang memberikan ulasan
-	Username       string             `bson:"username,omitempty" json:"username,omitempty"` // Nama pengguna yang memberikan ulasan
-	Rating         int                `bson:"rating,omitempty" json:"rating,omitempty"` // Rating (nilai ulasan)
-	ReviewText     string             `bson:"review_text,omitempty" json:"review_text,omitempty"` // Teks ulasan
+	ProductID      primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty" validate:"required,objectid"` // Foreign Key ke tabel Product
+	UserID         primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty" validate:"required,objectid"` // Foreign Key ke user yang memberikan ulasan
+	Username       string             `bson:"username,omitempty" json:"username,omitempty" validate:"max=32"` // Nama pengguna yang memberikan ulasan
+	Rating         int                `bson:"rating,omitempty" json:"rating,omitempty" validate:"required,min=1,max=5"` // Rating (nilai ulasan)
+	ReviewText     string             `bson:"review_text,omitempty" json:"review_text,omitempty" validate:"max=2000"` // Teks ulasan
 	ReviewImage    string             `bson:"review_image,omitempty" json:"review_image,omitempty"` // Foto yang disertakan dalam ulasan (opsional)
 	AdminResponse  string             `bson:"admin_response,omitempty" json:"admin_response,omitempty"` // Tanggapan admin (opsional)
 	ResponseDate   time.Time          `bson:"response_date,omitempty" json:"response_date,omitempty"` // Waktu tanggapan admin (opsional)
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Role staf selain RoleUser dan RoleAdmin
+const (
+	RoleWarehouse       = "warehouse"
+	RoleCustomerService = "customer_service"
+	RoleFinance         = "finance"
+)
+
+// Permission yang dapat diberikan ke sebuah role
+const (
+	PermissionAll                 = "*"                     // Semua permission, hanya untuk admin
+	PermissionCatalogWrite        = "catalog:write"         // Kelola kategori, produk dan banner
+	PermissionPaymentDetailsWrite = "payment_details:write" // Kelola rekening pembayaran
+	PermissionOrdersRead          = "orders:read"           // Melihat semua pesanan
+	PermissionOrdersWrite         = "orders:write"          // Mengubah dan menghapus pesanan
+	PermissionOrdersAdvance       = "orders:advance"        // Memajukan status pesanan
+	PermissionReviewsRespond      = "reviews:respond"       // Menanggapi ulasan
+	PermissionUsersManage         = "users:manage"          // Kelola profil dan role pengguna
+	PermissionRolesManage         = "roles:manage"          // Kelola role dan permission
+	PermissionAuditRead           = "audit:read"            // Melihat jejak audit
+	PermissionAPIKeysManage       = "api_keys:manage"       // Kelola API key integrasi
+)
+
+// Permissions adalah daftar permission yang dikenali sistem
+var Permissions = []string{
+	PermissionCatalogWrite,
+	PermissionPaymentDetailsWrite,
+	PermissionOrdersRead,
+	PermissionOrdersWrite,
+	PermissionOrdersAdvance,
+	PermissionReviewsRespond,
+	PermissionUsersManage,
+	PermissionRolesManage,
+	PermissionAuditRead,
+	PermissionAPIKeysManage,
+}
+
+// Role menyimpan sekumpulan permission yang dimiliki pengguna dengan role tersebut
+type Role struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name             string             `bson:"name" json:"name"`
+	Description      string             `bson:"description,omitempty" json:"description,omitempty"`
+	Permissions      []string           `bson:"permissions" json:"permissions"`
+	System           bool               `bson:"system" json:"system"`                         // Role bawaan tidak dapat dihapus
+	RequireTwoFactor bool               `bson:"require_two_factor" json:"require_two_factor"` // Wajib login memakai TOTP
+	CreatedAt        time.Time          `bson:"created_at,omitempty" json:"created_at,omitempty"`
+	UpdatedAt        time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
+
+// HasPermission mengecek apakah role memiliki permission tertentu
+func (r Role) HasPermission(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == PermissionAll || p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRoles adalah role bawaan yang disemai ke database saat aplikasi dijalankan
+var DefaultRoles = []Role{
+	{Name: RoleAdmin, Description: "Administrator dengan akses penuh", Permissions: []string{PermissionAll}, System: true},
+	{Name: RoleUser, Description: "Pelanggan", Permissions: []string{}, System: true},
+	{Name: RoleWarehouse, Description: "Staf gudang", Permissions: []string{PermissionOrdersRead, PermissionOrdersAdvance, PermissionCatalogWrite}},
+	{Name: RoleCustomerService, Description: "Customer service", Permissions: []string{PermissionOrdersRead, PermissionReviewsRespond}},
+	{Name: RoleFinance, Description: "Staf keuangan", Permissions: []string{PermissionPaymentDetailsWrite, PermissionOrdersRead, PermissionOrdersAdvance}},
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SessionRepository menyimpan model.Session
+type SessionRepository interface {
+	Repository[model.Session]
+	// IsActive mengecek bahwa sesi milik user belum dicabut dan belum kedaluwarsa
+	IsActive(ctx context.Context, sessionID, userID primitive.ObjectID) (bool, error)
+	// End menandai sesi aktif yang cocok dengan filter sebagai dicabut dan mengisi durasinya
+	End(ctx context.Context, filter bson.M) error
+}
+
+type sessionRepository struct {
+	*collection[model.Session]
+}
+
+func (r sessionRepository) IsActive(ctx context.Context, sessionID, userID primitive.ObjectID) (bool, error) {
+	count, err := r.Count(ctx, bson.M{
+		"_id":        sessionID,
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r sessionRepository) End(ctx context.Context, filter bson.M) error {
+	active := bson.M{"revoked_at": bson.M{"$exists": false}}
+	for key, value := range filter {
+		active[key] = value
+	}
+
+	sessions, err := r.Find(ctx, active)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, session := range sessions {
+		_, err := r.UpdateOne(ctx,
+			bson.M{"_id": session.ID, "revoked_at": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"revoked_at": now, "duration": int(now.Sub(session.Date).Seconds())}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefreshTokenRepository menyimpan model.RefreshToken
+type RefreshTokenRepository interface {
+	Repository[model.RefreshToken]
+	FindByHash(ctx context.Context, hash string) (model.RefreshToken, error)
+	// Revoke mencabut semua refresh token yang belum dicabut dan cocok dengan filter
+	Revoke(ctx context.Context, filter bson.M) error
+}
+
+type refreshTokenRepository struct {
+	*collection[model.RefreshToken]
+}
+
+func (r refreshTokenRepository) FindByHash(ctx context.Context, hash string) (model.RefreshToken, error) {
+	return r.FindOne(ctx, bson.M{"token_hash": hash})
+}
+
+func (r refreshTokenRepository) Revoke(ctx context.Context, filter bson.M) error {
+	active := bson.M{"revoked_at": bson.M{"$exists": false}}
+	for key, value := range filter {
+		active[key] = value
+	}
+	_, err := r.UpdateMany(ctx, active, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	return err
+}
+
+// LoginAttemptRepository menyimpan model.LoginAttempt
+type LoginAttemptRepository interface {
+	Repository[model.LoginAttempt]
+	FindByKey(ctx context.Context, key string) (model.LoginAttempt, error)
+	// RecordFailure menambah hitungan kegagalan key secara atomik dan mengembalikan catatan
+	// terbarunya. Catatan tanpa kunci aktif yang kegagalan terakhirnya sebelum since dihapus
+	// lebih dulu sehingga hitungan dimulai lagi dari satu.
+	RecordFailure(ctx context.Context, key string, since time.Time) (model.LoginAttempt, error)
+}
+
+type loginAttemptRepository struct {
+	*collection[model.LoginAttempt]
+}
+
+func (r loginAttemptRepository) FindByKey(ctx context.Context, key string) (model.LoginAttempt, error) {
+	return r.FindOne(ctx, bson.M{"key": key})
+}
+
+func (r loginAttemptRepository) RecordFailure(ctx context.Context, key string, since time.Time) (model.LoginAttempt, error) {
+	now := time.Now()
+	_, err := r.DeleteOne(ctx, bson.M{
+		"key":             key,
+		"last_failure_at": bson.M{"$lt": since},
+		"$or": bson.A{
+			bson.M{"locked_until": bson.M{"$exists": false}},
+			bson.M{"locked_until": bson.M{"$lte": now}},
+		},
+	})
+	if err != nil {
+		return model.LoginAttempt{}, err
+	}
+
+	var attempt model.LoginAttempt
+	err = r.coll.FindOneAndUpdate(ctx,
+		bson.M{"key": key},
+		bson.M{"$inc": bson.M{"failures": 1}, "$set": bson.M{"last_failure_at": now}},
+		&attempt,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	)
+	return attempt, err
+}
+
+// PasswordResetRepository menyimpan model.PasswordReset
+type PasswordResetRepository interface {
+	Repository[model.PasswordReset]
+	FindByHash(ctx context.Context, hash string) (model.PasswordReset, error)
+}
+
+type passwordResetRepository struct {
+	*collection[model.PasswordReset]
+}
+
+func (r passwordResetRepository) FindByHash(ctx context.Context, hash string) (model.PasswordReset, error) {
+	return r.FindOne(ctx, bson.M{"token_hash": hash})
+}
+
+// EmailVerificationRepository menyimpan model.EmailVerification
+type EmailVerificationRepository interface {
+	Repository[model.EmailVerification]
+	FindByHash(ctx context.Context, hash string) (model.EmailVerification, error)
+}
+
+type emailVerificationRepository struct {
+	*collection[model.EmailVerification]
+}
+
+func (r emailVerificationRepository) FindByHash(ctx context.Context, hash string) (model.EmailVerification, error) {
+	return r.FindOne(ctx, bson.M{"token_hash": hash})
+}
+
+// APIKeyRepository menyimpan model.APIKey
+type APIKeyRepository interface {
+	Repository[model.APIKey]
+	FindByHash(ctx context.Context, hash string) (model.APIKey, error)
+}
+
+type apiKeyRepository struct {
+	*collection[model.APIKey]
+}
+
+func (r apiKeyRepository) FindByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	return r.FindOne(ctx, bson.M{"key_hash": hash})
+}
+
+// AuditLogRepository menyimpan model.AuditLog. Entri hanya ditambahkan, tidak pernah diubah
+// atau dihapus, sehingga repository ini tidak menyediakan operasi update maupun delete.
+type AuditLogRepository interface {
+	Insert(ctx context.Context, entry model.AuditLog) error
+	// Find mengembalikan satu halaman entri terbaru lebih dulu beserta jumlah seluruh entri yang cocok
+	Find(ctx context.Context, filter bson.M, skip, limit int64) ([]model.AuditLog, int64, error)
+}
+
+type auditLogRepository struct {
+	entries *collection[model.AuditLog]
+}
+
+func (r auditLogRepository) Insert(ctx context.Context, entry model.AuditLog) error {
+	return r.entries.Insert(ctx, entry)
+}
+
+func (r auditLogRepository) Find(ctx context.Context, filter bson.M, skip, limit int64) ([]model.AuditLog, int64, error) {
+	total, err := r.entries.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+	entries, err := r.entries.Find(ctx, filter, opts)
+	return entries, total, err
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductRepository menyimpan model.Product
+type ProductRepository interface {
+	Repository[model.Product]
+}
+
+// CategoryRepository menyimpan model.Category
+type CategoryRepository interface {
+	Repository[model.Category]
+}
+
+// BannerRepository menyimpan model.Banner
+type BannerRepository interface {
+	Repository[model.Banner]
+}
+
+// ReviewRepository menyimpan model.Review
+type ReviewRepository interface {
+	Repository[model.Review]
+	FindByProduct(ctx context.Context, productID primitive.ObjectID) ([]model.Review, error)
+}
+
+type reviewRepository struct {
+	*collection[model.Review]
+}
+
+func (r reviewRepository) FindByProduct(ctx context.Context, productID primitive.ObjectID) ([]model.Review, error) {
+	return r.Find(ctx, bson.M{"product_id": productID})
+}
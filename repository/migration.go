@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"plastiqu_co/helper/atdb"
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SchemaMigrationCollection menyimpan model.SchemaMigration dan kunci migration
+const SchemaMigrationCollection = "schema_migrations"
+
+const (
+	// migrationLockID adalah _id dokumen kunci; versi migration selalu berupa angka
+	migrationLockID = "lock"
+	// migrationLockTTL membatasi umur kunci agar proses yang mati tidak menahannya selamanya
+	migrationLockTTL = 10 * time.Minute
+	// migrationLockPoll adalah jeda antar percobaan mengambil kunci yang sedang dipegang
+	migrationLockPoll = time.Second
+)
+
+// Migration adalah satu perubahan index atau data yang diterapkan tepat sekali, berurutan
+// menurut Version. Up harus idempoten: jika proses mati sebelum migration tercatat,
+// Up akan dijalankan ulang pada startup berikutnya.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db atdb.Database) error
+}
+
+// MigrationStatus adalah keadaan satu migration; AppliedAt kosong berarti masih pending
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrate menerapkan semua migration yang belum tercatat di schema_migrations dan
+// mengembalikan yang baru diterapkan. Instance lain yang bersamaan menunggu kunci
+// lalu melewati migration yang sudah diterapkan.
+func (r *Repositories) Migrate(ctx context.Context) ([]Migration, error) {
+	coll := r.db.Collection(SchemaMigrationCollection)
+	release, err := acquireMigrationLock(ctx, coll)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := appliedMigrations(ctx, coll)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range sortedMigrations() {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		start := time.Now()
+		if err := m.Up(ctx, r.db); err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+		}
+		record := model.SchemaMigration{
+			Version:    m.Version,
+			Name:       m.Name,
+			AppliedAt:  time.Now(),
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		if _, err := coll.InsertOne(ctx, record); err != nil {
+			return done, fmt.Errorf("record migration %d %s: %w", m.Version, m.Name, err)
+		}
+		slog.Info("migration applied", "version", m.Version, "name", m.Name, "duration_ms", record.DurationMS)
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// MigrationStatus mengembalikan semua migration yang dikenal beserta waktu penerapannya
+func (r *Repositories) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(ctx, r.db.Collection(SchemaMigrationCollection))
+	if err != nil {
+		return nil, err
+	}
+	var statuses []MigrationStatus
+	for _, m := range sortedMigrations() {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			status.AppliedAt = &record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func sortedMigrations() []Migration {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return sorted
+}
+
+func appliedMigrations(ctx context.Context, coll atdb.Collection) (map[int]model.SchemaMigration, error) {
+	var records []model.SchemaMigration
+	// Dokumen kunci tidak punya applied_at sehingga tidak ikut terbaca
+	if err := coll.Find(ctx, bson.M{"applied_at": bson.M{"$exists": true}}, &records); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]model.SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// acquireMigrationLock mengambil kunci di schema_migrations, menunggu selama kunci masih
+// dipegang proses lain dan belum kedaluwarsa. Fungsi release melepas kunci.
+func acquireMigrationLock(ctx context.Context, coll atdb.Collection) (release func(), err error) {
+	owner := primitive.NewObjectID().Hex()
+	if host, err := os.Hostname(); err == nil {
+		owner = host + "/" + owner
+	}
+
+	for {
+		now := time.Now()
+		// Upsert gagal dengan duplicate key pada _id jika kunci masih berlaku milik proses lain
+		_, err = coll.UpdateOne(ctx,
+			bson.M{"_id": migrationLockID, "expires_at": bson.M{"$lt": now}},
+			bson.M{"$set": bson.M{"owner": owner, "locked_at": now, "expires_at": now.Add(migrationLockTTL)}},
+			options.Update().SetUpsert(true),
+		)
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("acquire migration lock: %w", err)
+		}
+
+		slog.Info("waiting for migration lock held by another instance")
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("acquire migration lock: %w", ctx.Err())
+		case <-time.After(migrationLockPoll):
+		}
+	}
+
+	return func() {
+		// Tetap lepas kunci walaupun ctx migration sudah habis
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := coll.DeleteOne(ctx, bson.M{"_id": migrationLockID, "owner": owner}); err != nil {
+			slog.Error("failed to release migration lock", "error", err)
+		}
+	}, nil
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"plastiqu_co/helper/atdb"
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrations adalah daftar migration aplikasi. Tambahkan migration baru dengan versi
+// berikutnya; jangan mengubah atau menghapus migration yang sudah pernah dirilis.
+var migrations = []Migration{
+	{
+		// Data lama bisa berisi email berhuruf besar dan duplikat yang akan menggagalkan
+		// pembuatan index unik di migration berikutnya
+		Version: 1,
+		Name:    "users_normalize_and_dedupe",
+		Up:      normalizeUsers,
+	},
+	{
+		// Index unik (case-insensitive) untuk users.email dan users.username
+		Version: 2,
+		Name:    "users_unique_email_username",
+		Up: createIndexes(UserCollection,
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().
+					SetName(UserEmailIndex).
+					SetUnique(true).
+					SetCollation(CaseInsensitive).
+					SetPartialFilterExpression(bson.M{"email": bson.M{"$gt": ""}}),
+			},
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "username", Value: 1}},
+				Options: options.Index().
+					SetName(UserUsernameIndex).
+					SetUnique(true).
+					SetCollation(CaseInsensitive).
+					SetPartialFilterExpression(bson.M{"username": bson.M{"$gt": ""}}),
+			},
+		),
+	},
+	{
+		// Daftar sesi per pengguna dan pencarian API key berdasarkan hash
+		Version: 3,
+		Name:    "sessions_and_api_keys",
+		Up: steps(
+			createIndexes(SessionCollection,
+				mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_seen_at", Value: -1}}},
+			),
+			createIndexes(APIKeyCollection,
+				mongo.IndexModel{Keys: bson.D{{Key: "key_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			),
+		),
+	},
+	{
+		// Pencarian audit log berdasarkan waktu, aktor dan target
+		Version: 4,
+		Name:    "audit_log",
+		Up: createIndexes(AuditLogCollection,
+			mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "entity", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "action", Value: 1}, {Key: "created_at", Value: -1}}},
+		),
+	},
+	{
+		// Token sekali pakai dan percobaan login dicari berdasarkan hash atau key,
+		// refresh token juga dicabut per family dan per pengguna. Permintaan kirim ulang
+		// verifikasi hanya dibutuhkan selama 24 jam untuk pembatasan harian.
+		Version: 5,
+		Name:    "auth_token_lookups",
+		Up: steps(
+			createIndexes(RefreshTokenCollection,
+				mongo.IndexModel{Keys: bson.D{{Key: "token_hash", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "family_id", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			),
+			createIndexes(PasswordResetCollection,
+				mongo.IndexModel{Keys: bson.D{{Key: "token_hash", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			),
+			createIndexes(EmailVerificationCollection,
+				mongo.IndexModel{Keys: bson.D{{Key: "token_hash", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			),
+			createIndexes(VerificationRequestCollection,
+				mongo.IndexModel{Keys: bson.D{{Key: "email", Value: 1}, {Key: "created_at", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(24 * 60 * 60)},
+			),
+			createIndexes(LoginAttemptCollection,
+				mongo.IndexModel{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+			),
+		),
+	},
+	{
+		// Ulasan per produk, keranjang dan pesanan per pengguna, produk per kategori,
+		// nama role yang unik dan jumlah pengguna per role
+		Version: 6,
+		Name:    "catalog_and_order_lookups",
+		Up: steps(
+			createIndexes(ReviewCollection, mongo.IndexModel{Keys: bson.D{{Key: "product_id", Value: 1}}}),
+			createIndexes(CartCollection, mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}}),
+			createIndexes(OrderCollection, mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}}),
+			createIndexes(ProductCollection, mongo.IndexModel{Keys: bson.D{{Key: "category_id", Value: 1}}}),
+			createIndexes(RoleCollection, mongo.IndexModel{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)}),
+			createIndexes(UserCollection, mongo.IndexModel{Keys: bson.D{{Key: "role", Value: 1}}}),
+		),
+	},
+	{
+		// Akun lama dibuat sebelum ada role dan status; status kosong sudah diperlakukan
+		// sebagai aktif, kini ditulis eksplisit agar filter admin bekerja
+		Version: 7,
+		Name:    "backfill_user_role_and_status",
+		Up: func(ctx context.Context, db atdb.Database) error {
+			users := db.Collection(UserCollection)
+			missing := func(field string) bson.M {
+				return bson.M{"$or": bson.A{bson.M{field: bson.M{"$exists": false}}, bson.M{field: ""}}}
+			}
+			if _, err := users.UpdateMany(ctx, missing("role"), bson.M{"$set": bson.M{"role": model.RoleUser}}); err != nil {
+				return err
+			}
+			_, err := users.UpdateMany(ctx, missing("status"), bson.M{"$set": bson.M{"status": model.UserStatusActive}})
+			return err
+		},
+	},
+	{
+		// Daftar pesanan kini butuh orders:read; role staf bawaan yang sudah tersimpan
+		// mendapatkannya seperti role yang baru disemai
+		Version: 8,
+		Name:    "grant_orders_read_to_staff",
+		Up: func(ctx context.Context, db atdb.Database) error {
+			_, err := db.Collection(RoleCollection).UpdateMany(ctx,
+				bson.M{"name": bson.M{"$in": bson.A{model.RoleWarehouse, model.RoleCustomerService, model.RoleFinance}}},
+				bson.M{"$addToSet": bson.M{"permissions": model.PermissionOrdersRead}},
+			)
+			return err
+		},
+	},
+}
+
+// normalizeUsers merapikan email (huruf kecil tanpa spasi) dan username (tanpa spasi) lalu
+// menyelesaikan duplikat tanpa membedakan huruf besar. Akun tertua dipertahankan. Akun lain
+// dengan email yang sama ditangguhkan dan email-nya dipindah ke duplicate_email, sedangkan
+// username yang sama diberi akhiran dari ID akun. Setiap konflik dilaporkan ke log.
+func normalizeUsers(ctx context.Context, db atdb.Database) error {
+	users := db.Collection(UserCollection)
+	var docs []struct {
+		ID       primitive.ObjectID `bson:"_id"`
+		Email    string             `bson:"email"`
+		Username string             `bson:"username"`
+	}
+	// Urutan _id sama dengan urutan pembuatan akun
+	if err := users.Find(ctx, bson.M{}, &docs, options.Find().SetSort(bson.M{"_id": 1})); err != nil {
+		return err
+	}
+
+	emails := map[string]primitive.ObjectID{}
+	usernames := map[string]primitive.ObjectID{}
+	conflicts := 0
+	for _, user := range docs {
+		set, unset := bson.M{}, bson.M{}
+
+		email := strings.ToLower(strings.TrimSpace(user.Email))
+		if kept, taken := emails[email]; taken && email != "" {
+			slog.Warn("migration: duplicate email, suspending the newer account",
+				"user_id", user.ID.Hex(), "kept_user_id", kept.Hex())
+			conflicts++
+			now := time.Now()
+			set["duplicate_email"] = email
+			set["status"] = model.UserStatusSuspended
+			set["suspended_at"] = now
+			set["suspend_reason"] = "Email duplicates account " + kept.Hex()
+			set["updated_at"] = now
+			unset["email"] = ""
+		} else {
+			emails[email] = user.ID
+			if email != user.Email {
+				set["email"] = email
+			}
+		}
+
+		username := strings.TrimSpace(user.Username)
+		if kept, taken := usernames[strings.ToLower(username)]; taken && username != "" {
+			renamed := username + "_" + user.ID.Hex()[18:]
+			slog.Warn("migration: duplicate username, renaming the newer account",
+				"user_id", user.ID.Hex(), "kept_user_id", kept.Hex(), "username", renamed)
+			conflicts++
+			username = renamed
+		}
+		usernames[strings.ToLower(username)] = user.ID
+		if username != user.Username {
+			set["username"] = username
+		}
+
+		update := bson.M{}
+		if len(set) > 0 {
+			update["$set"] = set
+		}
+		if len(unset) > 0 {
+			update["$unset"] = unset
+		}
+		if len(update) == 0 {
+			continue
+		}
+		if _, err := users.UpdateOne(ctx, bson.M{"_id": user.ID}, update); err != nil {
+			return fmt.Errorf("normalize user %s: %w", user.ID.Hex(), err)
+		}
+	}
+	if conflicts > 0 {
+		slog.Warn("migration: resolved duplicate users; review the accounts listed above", "conflicts", conflicts)
+	}
+	return nil
+}
+
+// createIndexes membuat migration yang menambahkan index pada satu koleksi. CreateIndexes
+// idempoten selama definisi index tidak berubah.
+func createIndexes(collection string, models ...mongo.IndexModel) func(context.Context, atdb.Database) error {
+	return func(ctx context.Context, db atdb.Database) error {
+		return db.Collection(collection).CreateIndexes(ctx, models)
+	}
+}
+
+// steps menggabungkan beberapa langkah menjadi satu migration yang dijalankan berurutan
+func steps(fns ...func(context.Context, atdb.Database) error) func(context.Context, atdb.Database) error {
+	return func(ctx context.Context, db atdb.Database) error {
+		for _, fn := range fns {
+			if err := fn(ctx, db); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"plastiqu_co/helper/atdb"
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// migrated membuat Repositories di atas MemoryDatabase yang berisi users lalu menerapkan semua migration
+func migrated(t *testing.T, users ...bson.M) *Repositories {
+	t.Helper()
+	ctx := context.Background()
+	db := atdb.NewMemoryDatabase()
+	for _, user := range users {
+		if _, err := db.Collection(UserCollection).InsertOne(ctx, user); err != nil {
+			t.Fatalf("insert %v: %v", user, err)
+		}
+	}
+	repos := New(db)
+	if _, err := repos.Migrate(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return repos
+}
+
+func TestUserUniqueIndexes(t *testing.T) {
+	ctx := context.Background()
+	repos := migrated(t)
+	if err := repos.Users.Insert(ctx, model.Users{ID: primitive.NewObjectID(), Username: "ana", Email: "ana@example.com"}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		user      model.Users
+		duplicate bool
+	}{
+		{"same email in another case", model.Users{Username: "ana2", Email: "ANA@example.com"}, true},
+		{"same username in another case", model.Users{Username: "Ana", Email: "ana2@example.com"}, true},
+		{"different email and username", model.Users{Username: "budi", Email: "budi@example.com"}, false},
+		{"missing email is exempt", model.Users{Username: "no-email-1"}, false},
+		{"second missing email is exempt", model.Users{Username: "no-email-2"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.user.ID = primitive.NewObjectID()
+			err := repos.Users.Insert(ctx, tt.user)
+			if got := mongo.IsDuplicateKeyError(err); got != tt.duplicate {
+				t.Fatalf("duplicate key error = %v (err %v), want %v", got, err, tt.duplicate)
+			}
+			if !tt.duplicate && err != nil {
+				t.Fatalf("insert: %v", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeUsersResolvesDuplicates(t *testing.T) {
+	first, second, third := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	repos := migrated(t,
+		bson.M{"_id": first, "email": " Ana@Example.com ", "username": "ana"},
+		bson.M{"_id": second, "email": "ana@example.com", "username": "ANA "},
+		bson.M{"_id": third, "email": "budi@example.com", "username": "budi"},
+	)
+	ctx := context.Background()
+
+	kept, err := repos.Users.FindByID(ctx, first)
+	if err != nil {
+		t.Fatalf("find kept user: %v", err)
+	}
+	if kept.Email != "ana@example.com" || kept.Username != "ana" || kept.Status != model.UserStatusActive {
+		t.Errorf("kept user = %q %q %q, want the normalized email, the same username and active", kept.Email, kept.Username, kept.Status)
+	}
+
+	duplicate, err := repos.Users.FindByID(ctx, second)
+	if err != nil {
+		t.Fatalf("find duplicate user: %v", err)
+	}
+	if duplicate.Email != "" || duplicate.DuplicateEmail != "ana@example.com" || duplicate.Status != model.UserStatusSuspended {
+		t.Errorf("duplicate email = %q, duplicate_email = %q, status = %q; want the email moved and the account suspended",
+			duplicate.Email, duplicate.DuplicateEmail, duplicate.Status)
+	}
+	if want := "ANA_" + second.Hex()[18:]; duplicate.Username != want {
+		t.Errorf("duplicate username = %q, want %q", duplicate.Username, want)
+	}
+
+	other, err := repos.Users.FindByID(ctx, third)
+	if err != nil {
+		t.Fatalf("find other user: %v", err)
+	}
+	if other.Email != "budi@example.com" || other.Username != "budi" || other.Status != model.UserStatusActive {
+		t.Errorf("unrelated user changed: %+v", other)
+	}
+}
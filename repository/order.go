@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrderRepository menyimpan model.Orders
+type OrderRepository interface {
+	Repository[model.Orders]
+}
+
+// CartRepository menyimpan model.Cart
+type CartRepository interface {
+	Repository[model.Cart]
+	FindByUser(ctx context.Context, userID primitive.ObjectID) ([]model.Cart, error)
+}
+
+type cartRepository struct {
+	*collection[model.Cart]
+}
+
+func (r cartRepository) FindByUser(ctx context.Context, userID primitive.ObjectID) ([]model.Cart, error) {
+	return r.Find(ctx, bson.M{"user_id": userID})
+}
+
+// AddressRepository menyimpan model.Address
+type AddressRepository interface {
+	Repository[model.Address]
+}
+
+// PaymentDetailsRepository menyimpan model.PaymentDetails
+type PaymentDetailsRepository interface {
+	Repository[model.PaymentDetails]
+}
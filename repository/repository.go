@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+
+	"plastiqu_co/helper/atdb"
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nama koleksi untuk setiap entitas
+const (
+	UserCollection                = "users"
+	RoleCollection                = "roles"
+	ProductCollection             = "products"
+	CategoryCollection            = "categories"
+	BannerCollection              = "banners"
+	ReviewCollection              = "reviews"
+	OrderCollection               = "orders"
+	CartCollection                = "carts"
+	AddressCollection             = "addresses"
+	PaymentDetailsCollection      = "payment_details"
+	SessionCollection             = "sessions"
+	RefreshTokenCollection        = "refresh_tokens"
+	LoginAttemptCollection        = "login_attempts"
+	PasswordResetCollection       = "password_resets"
+	EmailVerificationCollection   = "email_verifications"
+	VerificationRequestCollection = "verification_requests"
+	APIKeyCollection              = "api_keys"
+	AuditLogCollection            = "audit_log"
+)
+
+// Repository adalah operasi dasar bertipe atas satu koleksi. Repository per entitas
+// menambahkan query khusus di atasnya.
+type Repository[T any] interface {
+	FindByID(ctx context.Context, id primitive.ObjectID) (T, error)
+	FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) (T, error)
+	Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]T, error)
+	Count(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error)
+	Insert(ctx context.Context, doc T) error
+	UpdateByID(ctx context.Context, id primitive.ObjectID, update interface{}) (*mongo.UpdateResult, error)
+	UpdateOne(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	UpdateMany(ctx context.Context, filter, update interface{}) (*mongo.UpdateResult, error)
+	DeleteByID(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error)
+	DeleteOne(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error)
+	// Snapshot mengambil dokumen mentah untuk diff audit; nil jika tidak ditemukan
+	Snapshot(ctx context.Context, filter interface{}) bson.M
+}
+
+// Repositories mengumpulkan seluruh repository aplikasi. Dibuat sekali di main
+// lalu diteruskan ke handler.
+type Repositories struct {
+	db atdb.Database
+
+	Users                UserRepository
+	Roles                RoleRepository
+	Products             ProductRepository
+	Categories           CategoryRepository
+	Banners              BannerRepository
+	Reviews              ReviewRepository
+	Orders               OrderRepository
+	Carts                CartRepository
+	Addresses            AddressRepository
+	PaymentDetails       PaymentDetailsRepository
+	Sessions             SessionRepository
+	RefreshTokens        RefreshTokenRepository
+	LoginAttempts        LoginAttemptRepository
+	PasswordResets       PasswordResetRepository
+	EmailVerifications   EmailVerificationRepository
+	VerificationRequests Repository[model.VerificationRequest]
+	APIKeys              APIKeyRepository
+	AuditLog             AuditLogRepository
+}
+
+// New membuat seluruh repository di atas database yang diberikan
+func New(db atdb.Database) *Repositories {
+	return &Repositories{
+		db: db,
+
+		Users:                userRepository{newCollection[model.Users](db, UserCollection)},
+		Roles:                roleRepository{newCollection[model.Role](db, RoleCollection)},
+		Products:             newCollection[model.Product](db, ProductCollection),
+		Categories:           newCollection[model.Category](db, CategoryCollection),
+		Banners:              newCollection[model.Banner](db, BannerCollection),
+		Reviews:              reviewRepository{newCollection[model.Review](db, ReviewCollection)},
+		Orders:               newCollection[model.Orders](db, OrderCollection),
+		Carts:                cartRepository{newCollection[model.Cart](db, CartCollection)},
+		Addresses:            newCollection[model.Address](db, AddressCollection),
+		PaymentDetails:       newCollection[model.PaymentDetails](db, PaymentDetailsCollection),
+		Sessions:             sessionRepository{newCollection[model.Session](db, SessionCollection)},
+		RefreshTokens:        refreshTokenRepository{newCollection[model.RefreshToken](db, RefreshTokenCollection)},
+		LoginAttempts:        loginAttemptRepository{newCollection[model.LoginAttempt](db, LoginAttemptCollection)},
+		PasswordResets:       passwordResetRepository{newCollection[model.PasswordReset](db, PasswordResetCollection)},
+		EmailVerifications:   emailVerificationRepository{newCollection[model.EmailVerification](db, EmailVerificationCollection)},
+		VerificationRequests: newCollection[model.VerificationRequest](db, VerificationRequestCollection),
+		APIKeys:              apiKeyRepository{newCollection[model.APIKey](db, APIKeyCollection)},
+		AuditLog:             auditLogRepository{newCollection[model.AuditLog](db, AuditLogCollection)},
+	}
+}
+
+// Ping memastikan database dapat dihubungi
+func (r *Repositories) Ping(ctx context.Context) error {
+	return r.db.Ping(ctx)
+}
+
+// collection mengimplementasikan Repository[T] di atas atdb.Collection
+type collection[T any] struct {
+	coll atdb.Collection
+}
+
+func newCollection[T any](db atdb.Database, name string) *collection[T] {
+	return &collection[T]{coll: db.Collection(name)}
+}
+
+func (c *collection[T]) FindByID(ctx context.Context, id primitive.ObjectID) (T, error) {
+	return c.FindOne(ctx, bson.M{"_id": id})
+}
+
+func (c *collection[T]) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) (T, error) {
+	var doc T
+	err := c.coll.FindOne(ctx, filter, &doc, opts...)
+	return doc, err
+}
+
+func (c *collection[T]) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+	docs := []T{}
+	if err := c.coll.Find(ctx, filter, &docs, opts...); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+func (c *collection[T]) Count(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return c.coll.CountDocuments(ctx, filter, opts...)
+}
+
+func (c *collection[T]) Insert(ctx context.Context, doc T) error {
+	_, err := c.coll.InsertOne(ctx, doc)
+	return err
+}
+
+func (c *collection[T]) UpdateByID(ctx context.Context, id primitive.ObjectID, update interface{}) (*mongo.UpdateResult, error) {
+	return c.coll.UpdateOne(ctx, bson.M{"_id": id}, update)
+}
+
+func (c *collection[T]) UpdateOne(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return c.coll.UpdateOne(ctx, filter, update, opts...)
+}
+
+func (c *collection[T]) UpdateMany(ctx context.Context, filter, update interface{}) (*mongo.UpdateResult, error) {
+	return c.coll.UpdateMany(ctx, filter, update)
+}
+
+func (c *collection[T]) DeleteByID(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error) {
+	return c.coll.DeleteOne(ctx, bson.M{"_id": id})
+}
+
+func (c *collection[T]) DeleteOne(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error) {
+	return c.coll.DeleteOne(ctx, filter)
+}
+
+func (c *collection[T]) Snapshot(ctx context.Context, filter interface{}) bson.M {
+	var doc bson.M
+	if err := c.coll.FindOne(ctx, filter, &doc); err != nil {
+		return nil
+	}
+	return doc
+}
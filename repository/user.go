@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nama index unik users, dipakai untuk menerjemahkan error duplicate key
+const (
+	UserEmailIndex    = "users_email_unique"
+	UserUsernameIndex = "users_username_unique"
+)
+
+// CaseInsensitive adalah collation yang dipakai oleh index unik users sehingga
+// "Budi@Mail.com" dan "budi@mail.com" dianggap sama
+var CaseInsensitive = &options.Collation{Locale: "en", Strength: 2}
+
+// UserRepository menyimpan model.Users
+type UserRepository interface {
+	Repository[model.Users]
+	// FindByEmail mencari user berdasarkan email tanpa membedakan huruf besar
+	FindByEmail(ctx context.Context, email string) (model.Users, error)
+}
+
+type userRepository struct {
+	*collection[model.Users]
+}
+
+func (r userRepository) FindByEmail(ctx context.Context, email string) (model.Users, error) {
+	return r.FindOne(ctx, bson.M{"email": email}, options.FindOne().SetCollation(CaseInsensitive))
+}
+
+// RoleRepository menyimpan model.Role
+type RoleRepository interface {
+	Repository[model.Role]
+	FindByName(ctx context.Context, name string) (model.Role, error)
+	// EnsureDefaults menyemai model.DefaultRoles jika belum ada. Role yang sudah ada
+	// tidak diubah sehingga permission yang diatur admin tetap terjaga.
+	EnsureDefaults(ctx context.Context) error
+}
+
+type roleRepository struct {
+	*collection[model.Role]
+}
+
+func (r roleRepository) FindByName(ctx context.Context, name string) (model.Role, error) {
+	return r.FindOne(ctx, bson.M{"name": name})
+}
+
+func (r roleRepository) EnsureDefaults(ctx context.Context) error {
+	now := time.Now()
+	for _, role := range model.DefaultRoles {
+		role.CreatedAt = now
+		role.UpdatedAt = now
+		_, err := r.UpdateOne(ctx,
+			bson.M{"name": role.Name},
+			bson.M{"$setOnInsert": role},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"plastiqu_co/controller"
+	"plastiqu_co/controller/auth"
+	"plastiqu_co/helper/openapi"
+	"plastiqu_co/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// apiInfo mengisi bagian info pada dokumen OpenAPI
+var apiInfo = openapi.Info{
+	Title:   "Plastiqu API",
+	Version: "1.0.0",
+	Description: "Plastiqu store backend. Every error uses the Envelope shape with a machine-readable `code`; " +
+		"validation errors list per-field messages in `fields`.",
+}
+
+var (
+	// message adalah respon sederhana {"message": "..."}
+	message = openapi.Object{"message": ""}
+
+	// tokens adalah respon login atau refresh yang berhasil
+	tokens = openapi.Object{
+		"message":            "",
+		"token":              "",
+		"token_type":         "",
+		"expires_at":         time.Time{},
+		"refresh_token":      "",
+		"refresh_expires_at": time.Time{},
+	}
+
+	// pageParams adalah query parameter paginasi, lihat controller.pagination
+	pageParams = []openapi.Param{
+		{Name: "page", Description: "Page number, starting at 1.", Example: 1},
+		{Name: "limit", Description: "Items per page, at most 100 (default 20).", Example: 20},
+	}
+
+	// idQuery dipakai handler yang membaca ID dari query string, bukan dari path
+	idQuery = []openapi.Param{{Name: "id", Description: "Document ID. The handler reads the ID from this query parameter, not from the path.", Required: true}}
+
+	// userIDQuery dipakai endpoint admin lama yang menerima ID pengguna lewat query string
+	userIDQuery = []openapi.Param{{Name: "user_id", Description: "User ID.", Required: true}}
+)
+
+// paged membentuk respon daftar berpaginasi dengan data bertipe data
+func paged(data interface{}) openapi.Object {
+	return openapi.Object{"data": data, "page": int64(0), "limit": int64(0), "total": int64(0)}
+}
+
+// operations mendokumentasikan setiap route pada InitializeRoutes. Route yang tidak ada di
+// sini tetap muncul di dokumen, tetapi dicatat sebagai peringatan saat startup.
+var operations = openapi.Operations{
+	// Health
+	"GET /healthz":      {Tag: "Health", Summary: "Liveness probe", Response: openapi.Object{"status": ""}},
+	"GET /readyz":       {Tag: "Health", Summary: "Readiness probe; checks the database connection", Response: openapi.Object{"status": ""}, Errors: []int{http.StatusServiceUnavailable}},
+	"GET /metrics":      {Tag: "Health", Summary: "Prometheus metrics", Description: "Text exposition format, not JSON."},
+	"GET /openapi.json": {Tag: "Health", Summary: "This OpenAPI document"},
+	"GET /docs":         {Tag: "Health", Summary: "Swagger UI for this document"},
+
+	// Auth
+	"POST /regis": {Tag: "Auth", Summary: "Register a new account", Request: model.Users{},
+		Description: "The account stays pending until the email address is verified.",
+		Response:    openapi.Object{"message": "", "user_id": primitive.ObjectID{}}, Errors: []int{http.StatusConflict}},
+	"POST /login": {Tag: "Auth", Summary: "Log in with email and password", Request: auth.LoginRequest{},
+		Description: "Returns tokens on success. Accounts with two-factor authentication instead receive " +
+			"`two_factor_required` and a `challenge_token` for POST /login/2fa; roles that require 2FA without " +
+			"an enrolled device receive `two_factor_enrollment_required` and an `enrollment_token`.",
+		Response: openapi.Object{
+			"message": "", "token": "", "token_type": "", "expires_at": time.Time{},
+			"refresh_token": "", "refresh_expires_at": time.Time{},
+			"user_id": "", "email": "", "username": "", "role": "", "session_id": "",
+		},
+		Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests}},
+	"POST /login/2fa": {Tag: "Auth", Summary: "Complete a two-factor login", Request: auth.TwoFactorLoginRequest{},
+		Description: "`code` accepts a TOTP code or an unused recovery code.",
+		Response:    tokens, Errors: []int{http.StatusUnauthorized, http.StatusTooManyRequests}},
+	"POST /auth/refresh":        {Tag: "Auth", Summary: "Rotate a refresh token", Request: auth.RefreshRequest{}, Response: tokens, Errors: []int{http.StatusUnauthorized}},
+	"POST /auth/logout":         {Tag: "Auth", Summary: "Revoke a refresh token and end its session", Request: auth.RefreshRequest{}, Response: message},
+	"GET /verify-email":         {Tag: "Auth", Summary: "Verify an email address", Query: []openapi.Param{{Name: "token", Description: "Token from the verification email.", Required: true}}, Response: message},
+	"POST /verify-email/resend": {Tag: "Auth", Summary: "Resend the verification email", Request: auth.EmailRequest{}, Response: message, Status: http.StatusAccepted, Errors: []int{http.StatusTooManyRequests}},
+	"POST /password/forgot":     {Tag: "Auth", Summary: "Request a password reset email", Request: auth.EmailRequest{}, Response: message, Status: http.StatusAccepted},
+	"POST /password/reset":      {Tag: "Auth", Summary: "Reset the password with a reset token", Request: auth.ResetPasswordRequest{}, Response: message},
+
+	// User
+	"PUT /user/profile":             {Tag: "User", Summary: "Update the caller's profile", Request: model.Users{}, Partial: true, Response: message, Errors: []int{http.StatusConflict}},
+	"POST /user/password":           {Tag: "User", Summary: "Change the caller's password", Request: controller.ChangePasswordRequest{}, Response: message},
+	"GET /user/sessions":            {Tag: "User", Summary: "List the caller's active sessions", Response: []model.Session{}},
+	"DELETE /user/sessions":         {Tag: "User", Summary: "Sign out all other sessions", Response: message},
+	"DELETE /user/sessions/{id}":    {Tag: "User", Summary: "Revoke one session", Response: message},
+	"POST /user/2fa/setup":          {Tag: "Two-factor", Summary: "Start TOTP enrollment", Description: "Also accepts an enrollment token from login.", Response: openapi.Object{"message": "", "secret": "", "otpauth_uri": ""}},
+	"POST /user/2fa/enable":         {Tag: "Two-factor", Summary: "Confirm TOTP enrollment", Description: "Also accepts an enrollment token from login. Recovery codes are returned only once.", Request: auth.TwoFactorRequest{}, Response: openapi.Object{"message": "", "recovery_codes": []string{}}},
+	"POST /user/2fa/disable":        {Tag: "Two-factor", Summary: "Disable two-factor authentication", Description: "Requires `password` and `code`.", Request: auth.TwoFactorRequest{}, Response: message},
+	"POST /user/2fa/recovery-codes": {Tag: "Two-factor", Summary: "Regenerate recovery codes", Request: auth.TwoFactorRequest{}, Response: openapi.Object{"message": "", "recovery_codes": []string{}}},
+
+	// Admin: pengguna
+	"PUT /admin/update-user-profile": {Tag: "Admin users", Summary: "Update any user's profile", Description: "Changing `role` also requires the roles:manage permission.", Query: userIDQuery, Request: model.Users{}, Partial: true, Response: message, Errors: []int{http.StatusConflict}},
+	"PUT /admin/update-user-role":    {Tag: "Admin users", Summary: "Promote a user to admin", Query: userIDQuery, Response: message},
+	"GET /admin/users": {Tag: "Admin users", Summary: "Search users", Response: paged([]model.Users{}), Query: append([]openapi.Param{
+		{Name: "email", Description: "Partial, case-insensitive match."},
+		{Name: "username", Description: "Partial, case-insensitive match."},
+		{Name: "phone", Description: "Partial, case-insensitive match."},
+		{Name: "role"},
+		{Name: "status", Description: "Account status; deleted accounts are hidden unless requested."},
+		{Name: "created_from", Description: "RFC3339 or YYYY-MM-DD."},
+		{Name: "created_to", Description: "RFC3339 or YYYY-MM-DD; a date includes the whole day."},
+		{Name: "include_deleted", Example: false},
+	}, pageParams...)},
+	"GET /admin/users/{id}":             {Tag: "Admin users", Summary: "Get a user", Response: model.Users{}},
+	"DELETE /admin/users/{id}":          {Tag: "Admin users", Summary: "Soft delete a user", Response: message},
+	"POST /admin/users/{id}/suspend":    {Tag: "Admin users", Summary: "Suspend a user and revoke their sessions", Description: "The body is optional.", Request: controller.SuspendUserRequest{}, Response: message},
+	"POST /admin/users/{id}/reactivate": {Tag: "Admin users", Summary: "Reactivate a suspended user", Response: message},
+	"POST /admin/users/{id}/unlock":     {Tag: "Admin users", Summary: "Clear failed login lockouts for a user", Response: message},
+
+	// Admin: role dan permission
+	"GET /admin/permissions":        {Tag: "Roles", Summary: "List known permissions", Response: []string{}},
+	"GET /admin/roles":              {Tag: "Roles", Summary: "List roles", Response: []model.Role{}},
+	"POST /admin/roles":             {Tag: "Roles", Summary: "Create a role", Request: controller.RoleRequest{}, Response: openapi.Object{"message": "", "role": model.Role{}}, Status: http.StatusCreated, Errors: []int{http.StatusConflict}},
+	"PUT /admin/roles/{name}":       {Tag: "Roles", Summary: "Update a role", Request: controller.RoleRequest{}, Response: message},
+	"DELETE /admin/roles/{name}":    {Tag: "Roles", Summary: "Delete a role", Response: message, Errors: []int{http.StatusConflict}},
+	"PUT /admin/users/{id}/role":    {Tag: "Roles", Summary: "Assign a role to a user", Request: controller.AssignRoleRequest{}, Response: openapi.Object{"message": "", "role": ""}},
+	"DELETE /admin/users/{id}/role": {Tag: "Roles", Summary: "Demote a user to the default role", Response: message},
+
+	"GET /admin/audit-log": {Tag: "Audit", Summary: "List audit log entries, newest first", Response: paged([]model.AuditLog{}), Query: append([]openapi.Param{
+		{Name: "actor_id"},
+		{Name: "action", Description: "E.g. user.update."},
+		{Name: "entity", Description: "Collection name, e.g. users."},
+		{Name: "entity_id"},
+		{Name: "from", Description: "RFC3339 or YYYY-MM-DD."},
+		{Name: "to", Description: "RFC3339 or YYYY-MM-DD; a date includes the whole day."},
+	}, pageParams...)},
+
+	// API key
+	"GET /admin/api-keys": {Tag: "API keys", Summary: "List API keys without their secrets", Response: []model.APIKey{}},
+	"POST /admin/api-keys": {Tag: "API keys", Summary: "Create an API key", Description: "The full key is returned only in this response.",
+		Request: controller.CreateAPIKeyRequest{}, Response: openapi.Object{"message": "", "key": "", "api_key": model.APIKey{}}, Status: http.StatusCreated},
+	"DELETE /admin/api-keys/{id}": {Tag: "API keys", Summary: "Revoke an API key", Response: message},
+
+	// Katalog
+	"POST /categories":        {Tag: "Categories", Summary: "Create a category", Request: model.Category{}, Response: openapi.Object{"message": "", "category": model.Category{}}, Status: http.StatusCreated},
+	"GET /categories":         {Tag: "Categories", Summary: "List categories", Response: []model.Category{}},
+	"GET /categories/{id}":    {Tag: "Categories", Summary: "Get a category", Response: model.Category{}},
+	"PUT /categories/{id}":    {Tag: "Categories", Summary: "Update a category", Request: model.Category{}, Partial: true, Response: message},
+	"DELETE /categories/{id}": {Tag: "Categories", Summary: "Delete a category", Response: message},
+
+	"POST /products":        {Tag: "Products", Summary: "Create a product", Request: model.Product{}, Response: model.Product{}, Status: http.StatusCreated},
+	"GET /products":         {Tag: "Products", Summary: "List products", Response: []model.Product{}},
+	"GET /products/{id}":    {Tag: "Products", Summary: "Get a product", Response: model.Product{}},
+	"PUT /products/{id}":    {Tag: "Products", Summary: "Update a product", Request: model.Product{}, Partial: true, Response: model.Product{}},
+	"DELETE /products/{id}": {Tag: "Products", Summary: "Delete a product", Status: http.StatusNoContent},
+
+	"POST /banners":        {Tag: "Banners", Summary: "Create a banner", Request: model.Banner{}, Response: openapi.Object{"message": "", "banner": model.Banner{}}, Status: http.StatusCreated},
+	"GET /banners":         {Tag: "Banners", Summary: "List banners", Response: []model.Banner{}},
+	"GET /banners/{id}":    {Tag: "Banners", Summary: "Get a banner", Response: model.Banner{}},
+	"PUT /banners/{id}":    {Tag: "Banners", Summary: "Update a banner", Request: model.Banner{}, Partial: true, Response: message},
+	"DELETE /banners/{id}": {Tag: "Banners", Summary: "Delete a banner", Response: message},
+
+	// Pembayaran dan pesanan
+	"POST /payment_details":        {Tag: "Payment details", Summary: "Create payment details", Request: model.PaymentDetails{}, Response: model.PaymentDetails{}, Status: http.StatusCreated},
+	"GET /payment_details":         {Tag: "Payment details", Summary: "List payment details", Response: []model.PaymentDetails{}},
+	"GET /payment_details/{id}":    {Tag: "Payment details", Summary: "Get payment details", Query: idQuery, Response: model.PaymentDetails{}},
+	"PUT /payment_details/{id}":    {Tag: "Payment details", Summary: "Update payment details", Query: idQuery, Request: model.PaymentDetails{}, Partial: true, Response: message},
+	"DELETE /payment_details/{id}": {Tag: "Payment details", Summary: "Delete payment details", Query: idQuery, Response: message},
+
+	"POST /orders":               {Tag: "Orders", Summary: "Place an order", Description: "The initial status follows the payment method. The order belongs to the caller unless the caller has orders:write and sets user_id.", Request: model.Orders{}, Response: model.Orders{}, Status: http.StatusCreated},
+	"GET /orders":                {Tag: "Orders", Summary: "List orders", Response: []model.Orders{}},
+	"GET /orders/{id}":           {Tag: "Orders", Summary: "Get an order", Response: model.Orders{}},
+	"PUT /orders/{id}":           {Tag: "Orders", Summary: "Update an order", Request: model.Orders{}, Partial: true, Response: model.Orders{}},
+	"DELETE /orders/{id}":        {Tag: "Orders", Summary: "Delete an order", Status: http.StatusNoContent},
+	"PATCH /orders/advance/{id}": {Tag: "Orders", Summary: "Advance an order to its next status", Description: "Returns the new status.", Response: ""},
+
+	"POST /carts":          {Tag: "Carts", Summary: "Add a product to a cart", Request: model.Cart{}, Response: model.Cart{}, Status: http.StatusCreated},
+	"GET /carts/{user_id}": {Tag: "Carts", Summary: "List a user's cart items", Response: []model.Cart{}},
+	"PUT /carts/{id}":      {Tag: "Carts", Summary: "Update a cart item", Request: model.Cart{}, Partial: true, Response: model.Cart{}},
+	"DELETE /carts/{id}":   {Tag: "Carts", Summary: "Remove a cart item", Status: http.StatusNoContent},
+
+	"POST /reviews":                      {Tag: "Reviews", Summary: "Create a review", Request: model.Review{}, Response: model.Review{}, Status: http.StatusCreated},
+	"GET /products/{product_id}/reviews": {Tag: "Reviews", Summary: "List a product's reviews", Response: []model.Review{}},
+	"PUT /reviews/{review_id}":           {Tag: "Reviews", Summary: "Update a review", Request: model.Review{}, Partial: true, Response: message},
+	"DELETE /reviews/{review_id}":        {Tag: "Reviews", Summary: "Delete a review", Response: message},
+	"POST /reviews/{review_id}/response": {Tag: "Reviews", Summary: "Respond to a review as admin", Request: controller.ReviewResponseRequest{}, Response: message},
+
+	"POST /addresses":        {Tag: "Addresses", Summary: "Create an address", Request: model.Address{}, Response: model.Address{}, Status: http.StatusCreated},
+	"GET /addresses":         {Tag: "Addresses", Summary: "List addresses", Response: []model.Address{}},
+	"GET /addresses/{id}":    {Tag: "Addresses", Summary: "Get an address", Query: idQuery, Response: model.Address{}},
+	"PUT /addresses/{id}":    {Tag: "Addresses", Summary: "Update an address", Query: idQuery, Request: model.Address{}, Partial: true, Response: message},
+	"DELETE /addresses/{id}": {Tag: "Addresses", Summary: "Delete an address", Query: idQuery, Response: message},
+}
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"plastiqu_co/controller/auth"
+	"plastiqu_co/helper/atdb"
+	"plastiqu_co/helper/mailer"
+	"plastiqu_co/helper/openapi"
+	"plastiqu_co/repository"
+)
+
+// Setiap route harus terdokumentasi di operations dan setiap operasi harus punya route
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	repos := repository.New(atdb.NewMemoryDatabase())
+	mail := auth.Mail{Sender: mailer.OutboxSender{Dir: t.TempDir(), From: "no-reply@example.com"}}
+	router := InitializeRoutes(repos, mail, time.UTC)
+
+	if err := openapi.New(apiInfo, operations).Build(router); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json: status %d", rec.Code)
+	}
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Security []map[string][]string `json:"security"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+
+	// Route yang dilindungi permission tercantum dengan skema keamanannya
+	if security := doc.Paths["/admin/users"]["get"].Security; len(security) != 1 || security[0][openapi.BearerAuth] == nil {
+		t.Errorf("GET /admin/users security = %v, want bearer only", security)
+	}
+	if security := doc.Paths["/orders"]["get"].Security; len(security) != 2 {
+		t.Errorf("GET /orders security = %v, want bearer or API key", security)
+	}
+}
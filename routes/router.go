@@ -1,85 +1,184 @@
 package routes
 
 import (
+	"log/slog"
+	"net/http"
+	"time"
+
 	"plastiqu_co/controller"
 	"plastiqu_co/controller/auth"
+	"plastiqu_co/helper/audit"
+	"plastiqu_co/helper/openapi"
+	"plastiqu_co/helper/response"
+	"plastiqu_co/helper/telemetry"
+	"plastiqu_co/middleware"
+	"plastiqu_co/model"
+	"plastiqu_co/repository"
 
 	"github.com/gorilla/mux"
 )
 
-// InitializeRoutes sets up the router
-func InitializeRoutes() *mux.Router {
+// InitializeRoutes sets up the router. Handler dan middleware dibuat di sini di atas repository yang sama;
+// mail dipakai untuk email verifikasi dan reset password, loc untuk filter tanggal.
+func InitializeRoutes(repos *repository.Repositories, mail auth.Mail, loc *time.Location) *mux.Router {
 	router := mux.NewRouter()
+	// 404 dan 405 bawaan mux berupa teks biasa; samakan dengan format error JSON
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.WriteError(w, r, response.NotFound("Not found", "The requested endpoint does not exist."))
+	})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.WriteError(w, r, response.New(http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed", "The endpoint does not support this HTTP method."))
+	})
+
+	guard := middleware.NewGuard(repos)
+	recorder := audit.NewRecorder(repos.AuditLog)
+	authHandler := auth.NewHandler(repos, guard, recorder, mail)
+	h := controller.NewHandler(repos, guard, recorder, authHandler, loc)
+
+	// authenticated membungkus handler dengan verifikasi token
+	authenticated := func(handler http.HandlerFunc) http.Handler {
+		return openapi.Secure(guard.Authenticate(handler), "", openapi.BearerAuth)
+	}
+	// permitted membungkus handler dengan verifikasi token atau API key dan hanya
+	// mengizinkan role atau API key yang memiliki permission tertentu
+	permitted := func(handler http.HandlerFunc, permission string) http.Handler {
+		return openapi.Secure(guard.AuthenticateOrAPIKey(guard.RequirePermission(permission)(handler)), permission, openapi.BearerAuth, openapi.APIKeyAuth)
+	}
+	// userPermitted seperti permitted, tetapi menolak API key
+	userPermitted := func(handler http.HandlerFunc, permission string) http.Handler {
+		return openapi.Secure(guard.Authenticate(guard.RequirePermission(permission)(handler)), permission, openapi.BearerAuth)
+	}
+
+	// Probe untuk load balancer dan orchestrator, serta metrik Prometheus
+	router.HandleFunc("/healthz", h.Healthz).Methods("GET")
+	router.HandleFunc("/readyz", h.Readyz).Methods("GET")
+	router.Handle("/metrics", telemetry.Handler()).Methods("GET")
+
+	// Dokumen OpenAPI dan Swagger UI. Dokumen dibangun di akhir, setelah semua route terdaftar.
+	docs := openapi.New(apiInfo, operations)
+	router.Handle("/openapi.json", docs).Methods("GET")
+	router.Handle("/docs", openapi.UI("/openapi.json")).Methods("GET")
 
 	// Define your routes here
-	router.HandleFunc("/regis", auth.RegisterUsers).Methods("POST")
-	router.HandleFunc("/login", auth.LoginUsers).Methods("POST")
+	router.HandleFunc("/regis", authHandler.RegisterUsers).Methods("POST")
+	router.HandleFunc("/login", authHandler.LoginUsers).Methods("POST")
+	router.HandleFunc("/login/2fa", authHandler.LoginTwoFactor).Methods("POST")
+	router.HandleFunc("/auth/refresh", authHandler.RefreshToken).Methods("POST")
+	router.HandleFunc("/auth/logout", authHandler.Logout).Methods("POST")
+	router.HandleFunc("/verify-email", authHandler.VerifyEmail).Methods("GET")
+	router.HandleFunc("/verify-email/resend", authHandler.ResendVerificationEmail).Methods("POST")
+	router.HandleFunc("/password/forgot", authHandler.ForgotPassword).Methods("POST")
+	router.HandleFunc("/password/reset", authHandler.ResetPassword).Methods("POST")
 
 	// User routes (for authenticated users)
-	router.HandleFunc("/user/profile", controller.UpdateUserProfile).Methods("PUT")
-	router.HandleFunc("/user/password", controller.ChangeUserPassword).Methods("POST")
+	router.Handle("/user/profile", authenticated(h.UpdateUserProfile)).Methods("PUT")
+	router.Handle("/user/password", authenticated(h.ChangeUserPassword)).Methods("POST")
+	router.Handle("/user/sessions", authenticated(authHandler.ListSessions)).Methods("GET")
+	router.Handle("/user/sessions", authenticated(authHandler.RevokeOtherSessions)).Methods("DELETE") // Keluar dari semua perangkat lain
+	router.Handle("/user/sessions/{id}", authenticated(authHandler.RevokeSession)).Methods("DELETE")
 
-	// Admin routes (only accessible to admin users)
+	// Two-factor (TOTP). Setup dan enable juga menerima token pendaftaran dari login
+	// bagi role yang mewajibkan 2FA.
+	enrollment := func(handler http.HandlerFunc) http.Handler {
+		return openapi.Secure(guard.AuthenticateEnrollment(handler), "", openapi.BearerAuth)
+	}
+	router.Handle("/user/2fa/setup", enrollment(authHandler.SetupTwoFactor)).Methods("POST")
+	router.Handle("/user/2fa/enable", enrollment(authHandler.EnableTwoFactor)).Methods("POST")
+	router.Handle("/user/2fa/disable", authenticated(authHandler.DisableTwoFactor)).Methods("POST")
+	router.Handle("/user/2fa/recovery-codes", authenticated(authHandler.RegenerateRecoveryCodes)).Methods("POST")
+
+	// Admin routes (only accessible to admin users). Pengguna, role dan audit log hanya
+	// dapat diakses lewat sesi pengguna, bukan API key.
 	// Route untuk admin memperbarui profil pengguna
-	router.HandleFunc("/admin/update-user-profile", controller.AdminUpdateUserProfile).Methods("PUT")
+	router.Handle("/admin/update-user-profile", userPermitted(h.AdminUpdateUserProfile, model.PermissionUsersManage)).Methods("PUT")
 	// Route untuk admin memperbarui peran pengguna
-	router.HandleFunc("/admin/update-user-role", controller.AdminUpdateUserRole).Methods("PUT")
+	router.Handle("/admin/update-user-role", userPermitted(h.AdminUpdateUserRole, model.PermissionRolesManage)).Methods("PUT")
+	// Manajemen pengguna: daftar & pencarian, suspend, soft delete.
+	// Demote dilakukan lewat DELETE /admin/users/{id}/role di bawah.
+	router.Handle("/admin/users", userPermitted(h.AdminListUsers, model.PermissionUsersManage)).Methods("GET")
+	router.Handle("/admin/users/{id}", userPermitted(h.AdminGetUser, model.PermissionUsersManage)).Methods("GET")
+	router.Handle("/admin/users/{id}", userPermitted(h.AdminDeleteUser, model.PermissionUsersManage)).Methods("DELETE")
+	router.Handle("/admin/users/{id}/suspend", userPermitted(h.AdminSuspendUser, model.PermissionUsersManage)).Methods("POST")
+	router.Handle("/admin/users/{id}/reactivate", userPermitted(h.AdminReactivateUser, model.PermissionUsersManage)).Methods("POST")
+	// Route untuk admin membuka kunci akun yang terkunci karena login gagal berulang
+	router.Handle("/admin/users/{id}/unlock", userPermitted(authHandler.AdminUnlockUser, model.PermissionUsersManage)).Methods("POST")
+
+	// Role & permission management
+	router.Handle("/admin/permissions", userPermitted(h.GetPermissions, model.PermissionRolesManage)).Methods("GET")
+	router.Handle("/admin/roles", userPermitted(h.GetRoles, model.PermissionRolesManage)).Methods("GET")
+	router.Handle("/admin/roles", userPermitted(h.CreateRole, model.PermissionRolesManage)).Methods("POST")
+	router.Handle("/admin/roles/{name}", userPermitted(h.UpdateRole, model.PermissionRolesManage)).Methods("PUT")
+	router.Handle("/admin/roles/{name}", userPermitted(h.DeleteRole, model.PermissionRolesManage)).Methods("DELETE")
+	router.Handle("/admin/users/{id}/role", userPermitted(h.AssignUserRole, model.PermissionRolesManage)).Methods("PUT")
+	router.Handle("/admin/users/{id}/role", userPermitted(h.RevokeUserRole, model.PermissionRolesManage)).Methods("DELETE") // Demote ke role "user"
+
+	// Jejak audit aksi administratif
+	router.Handle("/admin/audit-log", userPermitted(h.AdminListAuditLog, model.PermissionAuditRead)).Methods("GET")
+
+	// API key untuk integrasi antar server, hanya bisa dikelola oleh pengguna (bukan API key lain)
+	router.Handle("/admin/api-keys", userPermitted(h.GetAPIKeys, model.PermissionAPIKeysManage)).Methods("GET")
+	router.Handle("/admin/api-keys", userPermitted(h.CreateAPIKey, model.PermissionAPIKeysManage)).Methods("POST")
+	router.Handle("/admin/api-keys/{id}", userPermitted(h.RevokeAPIKey, model.PermissionAPIKeysManage)).Methods("DELETE")
 
 	// Endpoint untuk kategori
-	router.HandleFunc("/categories", controller.CreateCategory).Methods("POST")        // Membuat kategori baru
-	router.HandleFunc("/categories", controller.GetCategories).Methods("GET")          // Mengambil semua kategori
-	router.HandleFunc("/categories/{id}", controller.GetCategoryByID).Methods("GET")   // Mengambil kategori berdasarkan ID
-	router.HandleFunc("/categories/{id}", controller.UpdateCategory).Methods("PUT")    // Mengupdate kategori berdasarkan ID
-	router.HandleFunc("/categories/{id}", controller.DeleteCategory).Methods("DELETE") // Menghapus kategori berdasarkan ID
+	router.Handle("/categories", permitted(h.CreateCategory, model.PermissionCatalogWrite)).Methods("POST")        // Membuat kategori baru
+	router.HandleFunc("/categories", h.GetCategories).Methods("GET")          // Mengambil semua kategori
+	router.HandleFunc("/categories/{id}", h.GetCategoryByID).Methods("GET")   // Mengambil kategori berdasarkan ID
+	router.Handle("/categories/{id}", permitted(h.UpdateCategory, model.PermissionCatalogWrite)).Methods("PUT")    // Mengupdate kategori berdasarkan ID
+	router.Handle("/categories/{id}", permitted(h.DeleteCategory, model.PermissionCatalogWrite)).Methods("DELETE") // Menghapus kategori berdasarkan ID
 
 	// produk
-	router.HandleFunc("/products", controller.AddProduct).Methods("POST")           // Tambah produk
-	router.HandleFunc("/products", controller.GetAllProducts).Methods("GET")        // Dapatkan semua produk
-	router.HandleFunc("/products/{id}", controller.GetProductByID).Methods("GET")   // Dapatkan produk berdasarkan ID
-	router.HandleFunc("/products/{id}", controller.UpdateProduct).Methods("PUT")    // Update produk berdasarkan ID
-	router.HandleFunc("/products/{id}", controller.DeleteProduct).Methods("DELETE") // Hapus produk berdasarkan ID
+	router.Handle("/products", permitted(h.AddProduct, model.PermissionCatalogWrite)).Methods("POST")           // Tambah produk
+	router.HandleFunc("/products", h.GetAllProducts).Methods("GET")        // Dapatkan semua produk
+	router.HandleFunc("/products/{id}", h.GetProductByID).Methods("GET")   // Dapatkan produk berdasarkan ID
+	router.Handle("/products/{id}", permitted(h.UpdateProduct, model.PermissionCatalogWrite)).Methods("PUT")    // Update produk berdasarkan ID
+	router.Handle("/products/{id}", permitted(h.DeleteProduct, model.PermissionCatalogWrite)).Methods("DELETE") // Hapus produk berdasarkan ID
 
 	// PaymentDetails routes
-	router.HandleFunc("/payment_details", controller.CreatePaymentDetails).Methods("POST")
-	router.HandleFunc("/payment_details", controller.GetPaymentDetails).Methods("GET")
-	router.HandleFunc("/payment_details/{id}", controller.GetPaymentDetailByID).Methods("GET")
-	router.HandleFunc("/payment_details/{id}", controller.UpdatePaymentDetails).Methods("PUT")
-	router.HandleFunc("/payment_details/{id}", controller.DeletePaymentDetails).Methods("DELETE")
+	router.Handle("/payment_details", permitted(h.CreatePaymentDetails, model.PermissionPaymentDetailsWrite)).Methods("POST")
+	router.HandleFunc("/payment_details", h.GetPaymentDetails).Methods("GET")
+	router.HandleFunc("/payment_details/{id}", h.GetPaymentDetailByID).Methods("GET")
+	router.Handle("/payment_details/{id}", permitted(h.UpdatePaymentDetails, model.PermissionPaymentDetailsWrite)).Methods("PUT")
+	router.Handle("/payment_details/{id}", permitted(h.DeletePaymentDetails, model.PermissionPaymentDetailsWrite)).Methods("DELETE")
 
 	// Order routes
-	router.HandleFunc("/orders", controller.AddOrder).Methods("POST")                 // Menambahkan pesanan baru
-	router.HandleFunc("/orders", controller.GetAllOrders).Methods("GET")               // Mendapatkan semua pesanan
-	router.HandleFunc("/orders/{id}", controller.GetOrderByID).Methods("GET")          // Mendapatkan pesanan berdasarkan ID
-	router.HandleFunc("/orders/{id}", controller.UpdateOrder).Methods("PUT")           // Memperbarui pesanan berdasarkan ID
-	router.HandleFunc("/orders/{id}", controller.DeleteOrder).Methods("DELETE")        // Menghapus pesanan berdasarkan ID
-	router.HandleFunc("/orders/advance/{id}", controller.AdvanceOrderStatus).Methods("PATCH") // Mengubah status pesanan ke status berikutnya
+	router.Handle("/orders", authenticated(h.AddOrder)).Methods("POST")      // Menambahkan pesanan baru
+	router.Handle("/orders", permitted(h.GetAllOrders, model.PermissionOrdersRead)).Methods("GET")          // Mendapatkan semua pesanan
+	router.Handle("/orders/{id}", permitted(h.GetOrderByID, model.PermissionOrdersRead)).Methods("GET")     // Mendapatkan pesanan berdasarkan ID
+	router.Handle("/orders/{id}", permitted(h.UpdateOrder, model.PermissionOrdersWrite)).Methods("PUT")     // Memperbarui pesanan berdasarkan ID
+	router.Handle("/orders/{id}", permitted(h.DeleteOrder, model.PermissionOrdersWrite)).Methods("DELETE")  // Menghapus pesanan berdasarkan ID
+	router.Handle("/orders/advance/{id}", permitted(h.AdvanceOrderStatus, model.PermissionOrdersAdvance)).Methods("PATCH") // Mengubah status pesanan ke status berikutnya
 
 	// Cart routes
-	router.HandleFunc("/carts", controller.AddToCart).Methods("POST")                   // Menambahkan produk ke keranjang
-	router.HandleFunc("/carts/{user_id}", controller.GetCartItems).Methods("GET")       // Mendapatkan semua item di keranjang untuk pengguna tertentu
-	router.HandleFunc("/carts/{id}", controller.UpdateCartItem).Methods("PUT")          // Memperbarui item di keranjang berdasarkan ID
-	router.HandleFunc("/carts/{id}", controller.RemoveFromCart).Methods("DELETE")       // Menghapus item dari keranjang berdasarkan ID
+	router.HandleFunc("/carts", h.AddToCart).Methods("POST")                   // Menambahkan produk ke keranjang
+	router.HandleFunc("/carts/{user_id}", h.GetCartItems).Methods("GET")       // Mendapatkan semua item di keranjang untuk pengguna tertentu
+	router.HandleFunc("/carts/{id}", h.UpdateCartItem).Methods("PUT")          // Memperbarui item di keranjang berdasarkan ID
+	router.HandleFunc("/carts/{id}", h.RemoveFromCart).Methods("DELETE")       // Menghapus item dari keranjang berdasarkan ID
 	
 	// Review routes
-	router.HandleFunc("/reviews", controller.CreateReviewHandler).Methods("POST")                // Membuat ulasan baru
-	router.HandleFunc("/products/{product_id}/reviews", controller.GetReviewsHandler).Methods("GET") // Mengambil semua ulasan untuk produk tertentu
-	router.HandleFunc("/reviews/{review_id}", controller.UpdateReviewHandler).Methods("PUT")     // Memperbarui ulasan
-	router.HandleFunc("/reviews/{review_id}", controller.DeleteReviewHandler).Methods("DELETE")  // Menghapus ulasan
-	router.HandleFunc("/reviews/{review_id}/response", controller.AdminRespondReviewHandler).Methods("POST") // Admin menanggapi ulasan
+	router.HandleFunc("/reviews", h.CreateReviewHandler).Methods("POST")                // Membuat ulasan baru
+	router.HandleFunc("/products/{product_id}/reviews", h.GetReviewsHandler).Methods("GET") // Mengambil semua ulasan untuk produk tertentu
+	router.HandleFunc("/reviews/{review_id}", h.UpdateReviewHandler).Methods("PUT")     // Memperbarui ulasan
+	router.HandleFunc("/reviews/{review_id}", h.DeleteReviewHandler).Methods("DELETE")  // Menghapus ulasan
+	router.Handle("/reviews/{review_id}/response", permitted(h.AdminRespondReviewHandler, model.PermissionReviewsRespond)).Methods("POST") // Admin menanggapi ulasan
 
 	// banners
-	router.HandleFunc("/banners", controller.CreateBanner).Methods("POST")
-	router.HandleFunc("/banners", controller.GetBanners).Methods("GET")
-	router.HandleFunc("/banners/{id}", controller.GetBannerByID).Methods("GET")
-	router.HandleFunc("/banners/{id}", controller.UpdateBanner).Methods("PUT")
-	router.HandleFunc("/banners/{id}", controller.DeleteBanner).Methods("DELETE")
+	router.Handle("/banners", permitted(h.CreateBanner, model.PermissionCatalogWrite)).Methods("POST")
+	router.HandleFunc("/banners", h.GetBanners).Methods("GET")
+	router.HandleFunc("/banners/{id}", h.GetBannerByID).Methods("GET")
+	router.Handle("/banners/{id}", permitted(h.UpdateBanner, model.PermissionCatalogWrite)).Methods("PUT")
+	router.Handle("/banners/{id}", permitted(h.DeleteBanner, model.PermissionCatalogWrite)).Methods("DELETE")
 
 	// address
-	router.HandleFunc("/addresses", controller.CreateAddress).Methods("POST")
-	router.HandleFunc("/addresses", controller.GetAddresses).Methods("GET")
-	router.HandleFunc("/addresses/{id}", controller.GetAddressByID).Methods("GET")
-	router.HandleFunc("/addresses/{id}", controller.UpdateAddress).Methods("PUT")
-	router.HandleFunc("/addresses/{id}", controller.DeleteAddress).Methods("DELETE")
+	router.HandleFunc("/addresses", h.CreateAddress).Methods("POST")
+	router.HandleFunc("/addresses", h.GetAddresses).Methods("GET")
+	router.HandleFunc("/addresses/{id}", h.GetAddressByID).Methods("GET")
+	router.HandleFunc("/addresses/{id}", h.UpdateAddress).Methods("PUT")
+	router.HandleFunc("/addresses/{id}", h.DeleteAddress).Methods("DELETE")
+
+	if err := docs.Build(router); err != nil {
+		slog.Error("failed to build OpenAPI document", "error", err)
+	}
 
 	return router
 }